@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/containers/podman/v5/cmd/podman/common"
 	"github.com/containers/podman/v5/cmd/podman/registry"
@@ -101,7 +102,14 @@ func apply(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("Deploying to cluster...")
 
-	if err = registry.ContainerEngine().KubeApply(registry.Context(), reader, applyOptions); err != nil {
+	report, err := registry.ContainerEngine().KubeApply(registry.Context(), reader, applyOptions)
+	// The resources deployed before a failure are reported too.
+	if report != nil {
+		for _, resource := range report.Resources {
+			fmt.Printf("%s/%s %s\n", strings.ToLower(resource.Kind), resource.Name, resource.Status)
+		}
+	}
+	if err != nil {
 		return err
 	}
 
@@ -457,6 +457,12 @@ func teardown(body io.Reader, options entities.PlayKubeDownOptions) error {
 			fmt.Println(removed.Id)
 		}
 	}
+	if len(reports.KeptVolumes) > 0 {
+		fmt.Println("Volumes kept:")
+		for _, kept := range reports.KeptVolumes {
+			fmt.Println(kept)
+		}
+	}
 
 	lastPodRmError := podRmErrors.PrintErrors()
 	if lastPodRmError != nil {
@@ -517,6 +523,10 @@ func kubeplay(body io.Reader) error {
 // printPlayReport goes through the report returned by KubePlay and prints it out in a human
 // friendly format.
 func printPlayReport(report *entities.PlayKubeReport) error {
+	for _, w := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
 	// Print volumes report
 	for i, volume := range report.Volumes {
 		if i == 0 {
@@ -530,7 +540,12 @@ func printPlayReport(report *entities.PlayKubeReport) error {
 		if i == 0 {
 			fmt.Println("Secrets:")
 		}
-		fmt.Println(secret.CreateReport.ID)
+		// Secrets of a dry run are not created and have no ID.
+		if secret.CreateReport != nil && secret.CreateReport.ID != "" {
+			fmt.Println(secret.CreateReport.ID)
+		} else {
+			fmt.Println(secret.Name)
+		}
 	}
 
 	// Print pods report
@@ -178,6 +178,10 @@ const (
 	// MemoryNodesAnnotation is used to restrict memory allocations to specific memory nodes on NUMA systems
 	MemoryNodesAnnotation = "io.podman.annotations.memory-nodes"
 
+	// MaxKubeAnnotation is the max length of annotation values kept when
+	// long annotations are not requested.
+	MaxKubeAnnotation = 63
+
 	// TotalAnnotationSizeLimitB is the max length of annotations allowed by Kubernetes.
 	TotalAnnotationSizeLimitB int = 256 * (1 << 10) // 256 kB
 )
@@ -220,4 +220,8 @@ var (
 
 	// ErrHealthCheckTimeout indicates that a HealthCheck timed out.
 	ErrHealthCheckTimeout = errors.New("healthcheck command exceeded timeout")
+
+	// ErrKubeApplyConflict indicates that a server-side apply to a
+	// Kubernetes cluster conflicts with fields owned by another manager.
+	ErrKubeApplyConflict = errors.New("server-side apply conflict")
 )
@@ -20,6 +20,7 @@ import (
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/containers/podman/v5/pkg/env"
 	v1 "github.com/containers/podman/v5/pkg/k8s.io/api/core/v1"
+	networkingv1 "github.com/containers/podman/v5/pkg/k8s.io/api/networking/v1"
 	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/api/resource"
 	v12 "github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/util/intstr"
@@ -31,6 +32,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.podman.io/common/libnetwork/types"
 	"go.podman.io/common/pkg/config"
+	"go.podman.io/image/v5/manifest"
 )
 
 // GenerateForKube takes a slice of libpod containers and generates
@@ -505,6 +507,35 @@ func GenerateKubeServiceFromV1Pod(pod *v1.Pod, servicePorts []v1.ServicePort) (Y
 	return service, nil
 }
 
+// GenerateKubeNetworkPolicy generates a kube network policy for the network
+// only allowing ingress traffic to the pods labelled with one of the apps from
+// the pods labelled with one of the apps, that is the pods attached to it.
+func GenerateKubeNetworkPolicy(network string, apps []string) networkingv1.NetworkPolicy {
+	selector := v12.LabelSelector{
+		MatchExpressions: []v12.LabelSelectorRequirement{{
+			Key:      "app",
+			Operator: v12.LabelSelectorOpIn,
+			Values:   apps,
+		}},
+	}
+	return networkingv1.NetworkPolicy{
+		TypeMeta: v12.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: v12.ObjectMeta{
+			Name: removeUnderscores(network),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: selector,
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}},
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
 // servicePortState allows calling containerPortsToServicePorts for a single service
 type servicePortState struct {
 	// A program using the shared math/rand state with the default seed will produce the same sequence of pseudo-random numbers
@@ -593,7 +624,7 @@ func (p *Pod) podWithContainers(ctx context.Context, containers []*Container, po
 			if v, found := ctr.config.Spec.Annotations[define.UserNsAnnotation]; found {
 				podAnnotations[define.UserNsAnnotation] = v
 			}
-			_, _, infraDNS, _, err := containerToV1Container(ctx, ctr, getService)
+			_, _, infraDNS, _, err := containerToV1Container(ctx, ctr, getService, podmanOnly)
 			if err != nil {
 				return nil, err
 			}
@@ -638,7 +669,7 @@ func (p *Pod) podWithContainers(ctx context.Context, containers []*Container, po
 				stopTimeout = &ctr.config.StopTimeout
 			}
 
-			ctr, volumes, _, annotations, err := containerToV1Container(ctx, ctr, getService)
+			ctr, volumes, _, annotations, err := containerToV1Container(ctx, ctr, getService, podmanOnly)
 			if err != nil {
 				return nil, err
 			}
@@ -815,7 +846,7 @@ func simplePodWithV1Containers(ctx context.Context, ctrs []*Container, getServic
 		if !ctr.IDMappings().HostUIDMapping || !ctr.IDMappings().HostGIDMapping {
 			hostUsers = false
 		}
-		kubeCtr, kubeVols, ctrDNS, annotations, err := containerToV1Container(ctx, ctr, getService)
+		kubeCtr, kubeVols, ctrDNS, annotations, err := containerToV1Container(ctx, ctr, getService, podmanOnly)
 		if err != nil {
 			return nil, err
 		}
@@ -904,9 +935,49 @@ func getPodRestartPolicy(policy string) v1.RestartPolicy {
 	}
 }
 
+// healthCheckToLivenessProbe converts a healthcheck to an exec liveness probe,
+// it returns nil when there is no healthcheck or it is disabled.
+func healthCheckToLivenessProbe(hc *manifest.Schema2HealthConfig) *v1.Probe {
+	if hc == nil || len(hc.Test) < 2 {
+		return nil
+	}
+	var command []string
+	switch hc.Test[0] {
+	case define.HealthConfigTestCmd:
+		command = hc.Test[1:]
+	case define.HealthConfigTestCmdShell:
+		command = []string{"/bin/sh", "-c", strings.Join(hc.Test[1:], " ")}
+	default:
+		return nil
+	}
+	// Zero values are left unset for Kubernetes to apply its defaults, it
+	// rejects a failure threshold, timeout or period of zero.
+	probe := &v1.Probe{
+		Handler: v1.Handler{
+			Exec: &v1.ExecAction{Command: command},
+		},
+		InitialDelaySeconds: durationToSeconds(hc.StartPeriod),
+		TimeoutSeconds:      durationToSeconds(hc.Timeout),
+		PeriodSeconds:       durationToSeconds(hc.Interval),
+	}
+	if hc.Retries > 0 {
+		probe.FailureThreshold = int32(hc.Retries)
+	}
+	return probe
+}
+
+// durationToSeconds rounds d up to whole seconds, as probes only take seconds.
+// Durations that are not positive give zero.
+func durationToSeconds(d time.Duration) int32 {
+	if d <= 0 {
+		return 0
+	}
+	return int32((d + time.Second - 1) / time.Second)
+}
+
 // containerToV1Container converts information we know about a libpod container
 // to a V1.Container specification.
-func containerToV1Container(ctx context.Context, c *Container, getService bool) (v1.Container, []v1.Volume, *v1.PodDNSConfig, map[string]string, error) {
+func containerToV1Container(ctx context.Context, c *Container, getService, podmanOnly bool) (v1.Container, []v1.Volume, *v1.PodDNSConfig, map[string]string, error) {
 	kubeContainer := v1.Container{}
 	kubeVolumes := []v1.Volume{}
 	annotations := make(map[string]string)
@@ -1005,6 +1076,10 @@ func containerToV1Container(ctx context.Context, c *Container, getService bool)
 	}
 	kubeContainer.StdinOnce = false
 	kubeContainer.TTY = c.Terminal()
+	// The podman-only YAML keeps the healthcheck to podman, without a probe.
+	if !podmanOnly {
+		kubeContainer.LivenessProbe = healthCheckToLivenessProbe(c.HealthCheckConfig())
+	}
 
 	resources := c.LinuxResources()
 	if resources != nil {
@@ -0,0 +1,75 @@
+//go:build !remote
+
+package libpod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containers/podman/v5/libpod/define"
+	v1 "github.com/containers/podman/v5/pkg/k8s.io/api/core/v1"
+	"github.com/stretchr/testify/assert"
+	"go.podman.io/image/v5/manifest"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGenerateKubeNetworkPolicy(t *testing.T) {
+	policy := GenerateKubeNetworkPolicy("my_net", []string{"web", "db"})
+	b, err := yaml.Marshal(policy)
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  creationTimestamp: null
+  name: mynet
+spec:
+  ingress:
+  - from:
+    - podSelector:
+        matchExpressions:
+        - key: app
+          operator: In
+          values:
+          - web
+          - db
+  podSelector:
+    matchExpressions:
+    - key: app
+      operator: In
+      values:
+      - web
+      - db
+  policyTypes:
+  - Ingress
+`, string(b))
+}
+
+func TestHealthCheckToLivenessProbe(t *testing.T) {
+	assert.Nil(t, healthCheckToLivenessProbe(nil))
+	assert.Nil(t, healthCheckToLivenessProbe(&manifest.Schema2HealthConfig{Test: []string{define.HealthConfigTestNone}}))
+
+	probe := healthCheckToLivenessProbe(&manifest.Schema2HealthConfig{
+		Test:        []string{define.HealthConfigTestCmdShell, "curl -f http://localhost || exit 1"},
+		StartPeriod: 5 * time.Second,
+		Interval:    30 * time.Second,
+		Timeout:     1500 * time.Millisecond,
+		Retries:     4,
+	})
+	assert.Equal(t, &v1.Probe{
+		Handler: v1.Handler{
+			Exec: &v1.ExecAction{Command: []string{"/bin/sh", "-c", "curl -f http://localhost || exit 1"}},
+		},
+		InitialDelaySeconds: 5,
+		TimeoutSeconds:      2,
+		PeriodSeconds:       30,
+		FailureThreshold:    4,
+	}, probe)
+
+	// A healthcheck with default values leaves the probe defaults to Kubernetes.
+	probe = healthCheckToLivenessProbe(&manifest.Schema2HealthConfig{Test: []string{define.HealthConfigTestCmd, "pg_isready", "-U", "postgres"}})
+	assert.Equal(t, &v1.Probe{
+		Handler: v1.Handler{
+			Exec: &v1.ExecAction{Command: []string{"pg_isready", "-U", "postgres"}},
+		},
+	}, probe)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/containers/podman/v5/libpod/define"
@@ -123,3 +124,17 @@ func ValidateAnnotations(annotations map[string]string) error {
 
 	return nil
 }
+
+// LongValues returns the sorted keys of the annotations whose values are
+// longer than define.MaxKubeAnnotation, i.e. those that would be truncated
+// unless long annotations are used.
+func LongValues(annotations map[string]string) []string {
+	var keys []string
+	for k, v := range annotations {
+		if len(v) > define.MaxKubeAnnotation {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -78,3 +78,18 @@ func TestValidateAnnotations(t *testing.T) {
 		}
 	}
 }
+
+func TestLongValues(t *testing.T) {
+	keys := LongValues(map[string]string{
+		"short": "bar",
+		"max":   strings.Repeat("b", define.MaxKubeAnnotation),
+		"long":  strings.Repeat("b", define.MaxKubeAnnotation+1),
+		"a/b":   strings.Repeat("b", 2*define.MaxKubeAnnotation),
+	})
+	if strings.Join(keys, ",") != "a/b,long" {
+		t.Errorf("expected a/b,long, got %v", keys)
+	}
+	if keys := LongValues(nil); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+}
@@ -3,6 +3,7 @@
 package libpod
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,6 +12,7 @@ import (
 	api "github.com/containers/podman/v5/pkg/api/types"
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/containers/podman/v5/pkg/domain/infra/abi"
+	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/api/resource"
 	"github.com/gorilla/schema"
 	"go.podman.io/common/pkg/config"
 )
@@ -97,12 +99,18 @@ func GenerateKube(w http.ResponseWriter, r *http.Request) {
 	runtime := r.Context().Value(api.RuntimeKey).(*libpod.Runtime)
 	decoder := r.Context().Value(api.DecoderKey).(*schema.Decoder)
 	query := struct {
-		PodmanOnly bool     `schema:"podmanOnly"`
-		Names      []string `schema:"names"`
-		Service    bool     `schema:"service"`
-		Type       string   `schema:"type"`
-		Replicas   int32    `schema:"replicas"`
-		NoTrunc    bool     `schema:"noTrunc"`
+		PodmanOnly      bool     `schema:"podmanOnly"`
+		Names           []string `schema:"names"`
+		Service         bool     `schema:"service"`
+		Type            string   `schema:"type"`
+		Replicas        int32    `schema:"replicas"`
+		NoTrunc         bool     `schema:"noTrunc"`
+		NetworkPolicy   bool     `schema:"networkPolicy"`
+		Format          string   `schema:"format"`
+		Split           bool     `schema:"split"`
+		PVC             bool     `schema:"pvc"`
+		PVCStorageClass string   `schema:"pvcStorageClass"`
+		PVCSize         string   `schema:"pvcSize"`
 	}{
 		// Defaults would go here.
 		Replicas: 1,
@@ -112,6 +120,20 @@ func GenerateKube(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, http.StatusBadRequest, fmt.Errorf("failed to parse parameters for %s: %w", r.URL.String(), err))
 		return
 	}
+	if query.Format != "" && query.Format != "yaml" && query.Format != "jsonl" {
+		utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid format %q: must be yaml or jsonl", query.Format))
+		return
+	}
+	if query.Split && query.Format == "jsonl" {
+		utils.Error(w, http.StatusBadRequest, errors.New("split output is only supported with the yaml format"))
+		return
+	}
+	if query.PVCSize != "" {
+		if _, err := resource.ParseQuantity(query.PVCSize); err != nil {
+			utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid pvcSize %q: %w", query.PVCSize, err))
+			return
+		}
+	}
 
 	// Read the default kubeGenerateType from containers.conf it the user doesn't specify it
 	generateType := query.Type
@@ -131,6 +153,12 @@ func GenerateKube(w http.ResponseWriter, r *http.Request) {
 		Type:               generateType,
 		Replicas:           query.Replicas,
 		UseLongAnnotations: query.NoTrunc,
+		NetworkPolicy:      query.NetworkPolicy,
+		Format:             query.Format,
+		Split:              query.Split,
+		PVC:                query.PVC,
+		PVCStorageClass:    query.PVCStorageClass,
+		PVCSize:            query.PVCSize,
 	}
 	report, err := containerEngine.GenerateKube(r.Context(), query.Names, options)
 	if err != nil {
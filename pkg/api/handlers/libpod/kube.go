@@ -3,42 +3,314 @@
 package libpod
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"go.podman.io/storage/pkg/archive"
 
 	"github.com/containers/podman/v5/libpod"
+	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/annotations"
 	"github.com/containers/podman/v5/pkg/api/handlers/utils"
 	api "github.com/containers/podman/v5/pkg/api/types"
 	"github.com/containers/podman/v5/pkg/auth"
+	"github.com/containers/podman/v5/pkg/channel"
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	"github.com/containers/podman/v5/pkg/domain/infra/abi"
+	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/api/resource"
+	"github.com/containers/podman/v5/pkg/specgenutil"
+	"github.com/containers/podman/v5/pkg/util"
+	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/gorilla/schema"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
+	"go.podman.io/common/libimage"
+	"go.podman.io/common/libnetwork/etchosts"
+	"go.podman.io/image/v5/docker/reference"
+	ociarchive "go.podman.io/image/v5/oci/archive"
+	ocilayout "go.podman.io/image/v5/oci/layout"
 	"go.podman.io/image/v5/types"
 )
 
+// errContextTooLarge is returned when the decompressed build context exceeds
+// the budget given by the maxContextSize query parameter.
+var errContextTooLarge = errors.New("build context exceeds the maximum allowed size")
+
+// limitedReader returns errContextTooLarge once more than limit bytes have
+// been read from r. Contrary to io.LimitReader it does not silently truncate.
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, fmt.Errorf("%w of %d bytes", errContextTooLarge, l.limit)
+	}
+	return n, err
+}
+
+// checkDeclaredContextSize returns errContextTooLarge when the request
+// declares a body larger than maxContextSize. It is called for the bodies
+// extracted as a tar: compression rarely makes a tar larger, so the declared
+// length is a lower bound of the size checked while extracting.
+func checkDeclaredContextSize(r *http.Request, maxContextSize int64) error {
+	if maxContextSize <= 0 || r.ContentLength <= maxContextSize {
+		return nil
+	}
+	return fmt.Errorf("%w of %d bytes: the request declares %d bytes", errContextTooLarge, maxContextSize, r.ContentLength)
+}
+
+// errContextDigestMismatch is returned when the uploaded context does not
+// match the digest of the X-Context-SHA256 header.
+var errContextDigestMismatch = errors.New("build context does not match its X-Context-SHA256 digest")
+
+// contextDigestHeader carries the hex encoded SHA-256 of the uploaded tar.
+const contextDigestHeader = "X-Context-SHA256"
+
+// allowedDigestsHeader carries a JSON object mapping the images of the kube
+// YAML to the digest they must resolve to.
+const allowedDigestsHeader = "X-Allowed-Digests"
+
+// parseAllowedDigests decodes the value of the X-Allowed-Digests header.
+func parseAllowedDigests(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, nil
+	}
+	var allowed map[string]string
+	if err := json.Unmarshal([]byte(header), &allowed); err != nil {
+		return nil, fmt.Errorf("invalid %s header: %w", allowedDigestsHeader, err)
+	}
+	for image, dgst := range allowed {
+		if _, err := digest.Parse(dgst); err != nil {
+			return nil, fmt.Errorf("invalid %s header: digest of %s: %w", allowedDigestsHeader, image, err)
+		}
+	}
+	return allowed, nil
+}
+
+// cleanupWarningHeader is set on the response of a kube play request when its
+// scratch directory could not be removed.
+const cleanupWarningHeader = "X-Cleanup-Warning"
+
+// logLevelHeader selects the log level of a kube play request, the logLevel
+// query parameter takes precedence over it.
+const logLevelHeader = "X-Podman-Log-Level"
+
+// requestLogger returns a logger for the messages of a single request, writing
+// like the standard logger at level when more verbose than the level of the
+// service. The level of the service is left untouched.
+func requestLogger(level logrus.Level) *logrus.Entry {
+	std := logrus.StandardLogger()
+	logger := &logrus.Logger{
+		Out:          std.Out,
+		Formatter:    std.Formatter,
+		Hooks:        std.Hooks,
+		ReportCaller: std.ReportCaller,
+		ExitFunc:     std.ExitFunc,
+		Level:        max(level, std.GetLevel()),
+	}
+	return logrus.NewEntry(logger)
+}
+
+// removeContextDir removes the scratch directory of a kube play request and
+// returns a warning for the client when it cannot be removed.
+func removeContextDir(dir string) string {
+	if err := os.RemoveAll(dir); err != nil {
+		warning := fmt.Sprintf("failed to remove libpod_kube tmp directory %q: %v", dir, err)
+		logrus.Warn(warning)
+		return warning
+	}
+	return ""
+}
+
+// extractTarFile extracts the (possibly compressed) tar stream r into anchorDir.
+// When maxSize is greater than zero, the extraction is aborted as soon as more
+// than maxSize bytes have been read from the decompressed stream. When digest
+// is set, the SHA-256 of the stream, as sent, must match it.
+func extractTarFile(anchorDir string, r io.Reader, maxSize int64, digest string) error {
+	var hasher hash.Hash
+	if digest != "" {
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+			return fmt.Errorf("%w: invalid digest %q", errContextDigestMismatch, digest)
+		}
+		hasher = sha256.New()
+		r = io.TeeReader(r, hasher)
+	}
+
+	if err := untar(anchorDir, r, maxSize); err != nil {
+		return err
+	}
+
+	if hasher == nil {
+		return nil
+	}
+	// The tar reader stops at the end of archive marker, hash the padding too.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(sum, digest) {
+		return fmt.Errorf("%w: got %s, expected %s", errContextDigestMismatch, sum, digest)
+	}
+	return nil
+}
+
+// untar extracts the (possibly compressed) tar stream r into anchorDir, see
+// extractTarFile.
+func untar(anchorDir string, r io.Reader, maxSize int64) error {
+	if maxSize <= 0 {
+		return archive.Untar(r, anchorDir, nil)
+	}
+
+	decompressed, err := archive.DecompressStream(r)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+
+	return archive.UntarUncompressed(&limitedReader{r: decompressed, limit: maxSize}, anchorDir, nil)
+}
+
+// kubePullPolicies are the values accepted by the pullPolicy parameter.
+var kubePullPolicies = []string{"always", "missing", "never", "newer"}
+
+// validatePullPolicy makes sure the pullPolicy parameter, when set, is one of
+// kubePullPolicies.
+func validatePullPolicy(policy string) error {
+	if policy == "" || slices.Contains(kubePullPolicies, policy) {
+		return nil
+	}
+	return fmt.Errorf("invalid pullPolicy %q: must be one of %s", policy, strings.Join(kubePullPolicies, ", "))
+}
+
+// validateCgroupParent makes sure the cgroupParent parameter names a cgroup.
+func validateCgroupParent(parent string) error {
+	if parent == "" {
+		return errors.New("invalid cgroupParent: must not be empty")
+	}
+	if strings.ContainsAny(parent, "\x00\n\r") {
+		return fmt.Errorf("invalid cgroupParent %q: must not contain null or newline characters", parent)
+	}
+	return nil
+}
+
+// validateRegistryMirror makes sure the mirror parameter is a registry, with
+// an optional repository prefix, the images can be pulled from.
+func validateRegistryMirror(mirror string) error {
+	if mirror == "" {
+		return nil
+	}
+	if _, err := reference.ParseNamed(mirror + "/image"); err != nil {
+		return fmt.Errorf("invalid mirror %q: must be a registry host, optionally followed by a repository prefix: %w", mirror, err)
+	}
+	return nil
+}
+
+// validatePublishPorts makes sure every publishPorts entry follows the
+// [[ip:]hostPort[-endPort]:]containerPort[-endPort][/protocol] format so a
+// malformed value is reported to the client instead of failing deep in PlayKube.
+func validatePublishPorts(ports []string) error {
+	for _, port := range ports {
+		mappings, err := specgenutil.CreatePortBindings([]string{port})
+		if err != nil {
+			return fmt.Errorf("invalid publishPorts %q: %w", port, err)
+		}
+		for _, mapping := range mappings {
+			if mapping.Protocol == "" {
+				continue
+			}
+			for proto := range strings.SplitSeq(mapping.Protocol, ",") {
+				switch proto {
+				case "tcp", "udp", "sctp":
+				default:
+					return fmt.Errorf("invalid publishPorts %q: unsupported protocol %q", port, proto)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateAddHosts makes sure every addHost entry follows the
+// name[;name...]:ip format of --add-host, where ip may be host-gateway.
+func validateAddHosts(hosts []string) error {
+	for _, host := range hosts {
+		names, ip, hasIP := strings.Cut(host, ":")
+		if !hasIP || names == "" {
+			return fmt.Errorf("invalid addHost %q: must be in the host:ip format", host)
+		}
+		for name := range strings.SplitSeq(names, ";") {
+			if name == "" {
+				return fmt.Errorf("invalid addHost %q: empty hostname", host)
+			}
+		}
+		if ip != etchosts.HostGateway && net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid addHost %q: invalid IP address %q", host, ip)
+		}
+	}
+	return nil
+}
+
 // ExtractPlayReader provide an io.Reader given a http.Request object
-// the function will extract the Content-Type header, if not provided, the body will be returned
+// the function will extract the Content-Type header, if not provided, the body is sniffed and handled as a tar or returned
 // of the header define a text format (json, yaml or text) it will also return the body
 // if the Content-Type is tar, we extract the content to the anchorDir and try to read the `play.yaml` file
-func extractPlayReader(anchorDir string, r *http.Request) (io.Reader, error) {
+// maxContextSize bounds the decompressed size of a tar body, 0 means unlimited.
+func extractPlayReader(anchorDir string, r *http.Request, maxContextSize int64) (io.Reader, error) {
+	// A tar declaring a length over the budget is refused before being
+	// extracted, tars of unknown length are bounded while extracted.
+	extractTar := func(body io.Reader) (io.Reader, error) {
+		if err := checkDeclaredContextSize(r, maxContextSize); err != nil {
+			return nil, err
+		}
+		return extractPlayTar(anchorDir, body, maxContextSize, r.Header.Get(contextDigestHeader))
+	}
+
 	hdr, found := r.Header["Content-Type"]
 
-	// If Content-Type is not specific we use the body
+	// If Content-Type is not specific we sniff the body, clients omitting
+	// the header may still send a tar
 	if !found || len(hdr) == 0 {
-		return r.Body, nil
+		body, isTar, err := sniffPlayBody(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if isTar {
+			return extractTar(body)
+		}
+		return body, nil
+	}
+
+	// Parameters such as charset do not change how the body is read
+	contentType := hdr[0]
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
 	}
 
 	var reader io.Reader
-	switch hdr[0] {
+	switch contentType {
 	// backward compatibility
 	case "text/plain":
 		fallthrough
@@ -51,58 +323,281 @@ func extractPlayReader(anchorDir string, r *http.Request) (io.Reader, error) {
 	case "application/x-yaml":
 		reader = r.Body
 	case "application/x-tar":
-		// un-tar the content
-		err := archive.Untar(r.Body, anchorDir, nil)
+		return extractTar(r.Body)
+	default:
+		return nil, fmt.Errorf("Content-Type: %s is not supported. Should be \"application/x-tar\"", hdr[0])
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// sniffPlayBody reads the first bytes of body to tell whether it is a,
+// possibly gzip compressed, tar rather than YAML or JSON. The returned reader
+// replays the sniffed bytes.
+func sniffPlayBody(body io.Reader) (io.Reader, bool, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, false, err
+	}
+	head = head[:n]
+	reader := io.MultiReader(bytes.NewReader(head), body)
+
+	block := head
+	if bytes.HasPrefix(head, []byte{0x1f, 0x8b}) {
+		gr, err := gzip.NewReader(bytes.NewReader(head))
 		if err != nil {
-			return nil, err
+			return reader, false, nil
 		}
+		block = make([]byte, 512)
+		// head may hold only part of the compressed stream
+		n, _ := io.ReadFull(gr, block)
+		block = block[:n]
+	}
+	// the ustar magic of the first header, set by POSIX and GNU tars
+	const magicOffset = 257
+	isTar := len(block) > magicOffset+5 && bytes.Equal(block[magicOffset:magicOffset+5], []byte("ustar"))
+	return reader, isTar, nil
+}
+
+// extractPlayTar extracts the tar stream r into anchorDir, see extractTarFile,
+// and returns a reader of its play.yaml file.
+func extractPlayTar(anchorDir string, r io.Reader, maxContextSize int64, digest string) (io.Reader, error) {
+	// un-tar the content
+	if err := extractTarFile(anchorDir, r, maxContextSize, digest); err != nil {
+		return nil, err
+	}
+
+	// check for play.yaml
+	data, err := os.ReadFile(filepath.Join(anchorDir, "play.yaml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("file not found: tar missing play.yaml file at root")
+	} else if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
 
-		// check for play.yaml
-		path := filepath.Join(anchorDir, "play.yaml")
-		// open the play.yaml file
-		f, err := os.Open(path)
+// loadContextImages loads the OCI archives of the images folder of the play
+// context into the local storage. Unless replace is set, an archive is skipped
+// when the image it is named after is already in the local storage.
+func loadContextImages(ctx context.Context, logger *logrus.Entry, runtime *libimage.Runtime, contextDir string, replace bool) error {
+	imagesDir := filepath.Join(contextDir, "images")
+	entries, err := os.ReadDir(imagesDir)
+	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("file not found: tar missing play.yaml file at root")
-		} else if err != nil {
-			return nil, err
+			return nil
 		}
-		defer f.Close()
-		reader = f
-	default:
-		return nil, fmt.Errorf("Content-Type: %s is not supported. Should be \"application/x-tar\"", hdr[0])
+		return err
 	}
 
-	data, err := io.ReadAll(reader)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		ref, err := ociarchive.NewReference(filepath.Join(imagesDir, entry.Name()), "")
+		if err != nil {
+			return fmt.Errorf("loading image archive %s: %w", entry.Name(), err)
+		}
+		if !replace {
+			desc, err := ociarchive.LoadManifestDescriptor(ref)
+			if err != nil {
+				return fmt.Errorf("loading image archive %s: %w", entry.Name(), err)
+			}
+			if name := desc.Annotations[imgspecv1.AnnotationRefName]; name != "" {
+				exists, err := runtime.Exists(name)
+				if err != nil {
+					return err
+				}
+				if exists {
+					logger.Debugf("Skipping image archive %s: %s is already in the local storage", entry.Name(), name)
+					continue
+				}
+			}
+		}
+		names, err := runtime.LoadReference(ctx, ref, nil)
+		if err != nil {
+			return fmt.Errorf("loading image archive %s: %w", entry.Name(), err)
+		}
+		logger.Debugf("Loaded %v from image archive %s", names, entry.Name())
+	}
+	return nil
+}
+
+// errInvalidEnvFile is returned for an envFile missing from the play context
+// or holding malformed lines.
+var errInvalidEnvFile = errors.New("invalid env file")
+
+// readContextEnvFile reads the KEY=VALUE lines of the file name of the play
+// context. Empty lines and lines starting with # are ignored.
+func readContextEnvFile(contextDir, name string) (map[string]string, error) {
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("%w %s: must be a relative path in the play context", errInvalidEnvFile, name)
+	}
+	// Do not follow links out of the context, in any component of the path.
+	root, err := os.OpenRoot(contextDir)
 	if err != nil {
 		return nil, err
 	}
-	return bytes.NewReader(data), nil
+	defer root.Close()
+	f, err := root.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w %s: not found in the play context", errInvalidEnvFile, name)
+		}
+		return nil, fmt.Errorf("%w %s: %v", errInvalidEnvFile, name, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w %s: not a regular file", errInvalidEnvFile, name)
+	}
+	return parseEnvFile(name, f)
+}
+
+// parseEnvFile parses the KEY=VALUE lines of r, the env file name.
+func parseEnvFile(name string, r io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimLeft(scanner.Text(), " \t")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(text, "=")
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%w %s: line %d: must be in the KEY=VALUE format", errInvalidEnvFile, name, line)
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading env file %s: %w", name, err)
+	}
+	return env, nil
+}
+
+// errInvalidContextImage is returned for an oci: image that does not point at
+// an OCI layout of the play context.
+var errInvalidContextImage = errors.New("invalid play context image")
+
+// ociImageRegexp matches the image fields of a kube YAML using the oci:
+// transport, the reference is the second group.
+var ociImageRegexp = regexp.MustCompile(`(?m)^(\s*(?:-\s+)?image:\s*["']?)oci:([^"'\s#]+)(["']?)`)
+
+// resolveContextOCIImages rewrites the oci:<relative-path>[:<reference>]
+// images of kubeYAML to the IDs returned by load, which loads the OCI layout
+// of the play context into the local storage. Each layout is loaded once.
+func resolveContextOCIImages(kubeYAML []byte, load func(ref string) (string, error)) ([]byte, error) {
+	var loadErr error
+	loaded := make(map[string]string)
+	resolved := ociImageRegexp.ReplaceAllFunc(kubeYAML, func(match []byte) []byte {
+		if loadErr != nil {
+			return match
+		}
+		groups := ociImageRegexp.FindSubmatch(match)
+		ref := string(groups[2])
+		id, ok := loaded[ref]
+		if !ok {
+			if id, loadErr = load(ref); loadErr != nil {
+				return match
+			}
+			loaded[ref] = id
+		}
+		return slices.Concat(groups[1], []byte(id), groups[3])
+	})
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return resolved, nil
+}
+
+// contextOCILayout returns the path of the OCI layout of the image ref, a
+// <relative-path>[:<reference>] of contextDir, and the reference in the
+// layout. The layout may not leave the context, through a symlink either.
+func contextOCILayout(contextDir, ref string) (string, string, error) {
+	dir, image, _ := strings.Cut(ref, ":")
+	if !filepath.IsLocal(dir) {
+		return "", "", fmt.Errorf("%w oci:%s: the OCI layout must be a relative path in the play context", errInvalidContextImage, ref)
+	}
+	root, err := os.OpenRoot(contextDir)
+	if err != nil {
+		return "", "", err
+	}
+	defer root.Close()
+	if _, err := root.Stat(filepath.Join(dir, "index.json")); err != nil {
+		return "", "", fmt.Errorf("%w oci:%s: no OCI layout at %s in the play context: %v", errInvalidContextImage, ref, dir, err)
+	}
+	path, err := securejoin.SecureJoin(contextDir, dir)
+	if err != nil {
+		return "", "", err
+	}
+	return path, image, nil
+}
+
+// loadContextOCIImage loads the image ref, a <relative-path>[:<reference>] OCI
+// layout of contextDir, into the local storage and returns its ID.
+func loadContextOCIImage(ctx context.Context, logger *logrus.Entry, runtime *libimage.Runtime, contextDir, ref string) (string, error) {
+	path, image, err := contextOCILayout(contextDir, ref)
+	if err != nil {
+		return "", err
+	}
+	dir, _, _ := strings.Cut(ref, ":")
+	layoutRef, err := ocilayout.NewReference(path, image)
+	if err != nil {
+		return "", fmt.Errorf("%w oci:%s: %v", errInvalidContextImage, ref, err)
+	}
+	names, err := runtime.LoadReference(ctx, layoutRef, nil)
+	if err != nil {
+		return "", fmt.Errorf("loading image oci:%s: %w", ref, err)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("loading image oci:%s: no image loaded", ref)
+	}
+	img, _, err := runtime.LookupImage(names[0], nil)
+	if err != nil {
+		return "", fmt.Errorf("loading image oci:%s: %w", ref, err)
+	}
+	logger.Debugf("Loaded %v from OCI layout %s", names, dir)
+	return img.ID(), nil
+}
+
+// kubeTmpDir returns the directory the play contexts are extracted in: the
+// image_copy_tmp_dir of containers.conf, or TMPDIR, when set, and the default
+// temporary directory otherwise.
+func kubeTmpDir(runtime *libpod.Runtime) (string, error) {
+	config, err := runtime.GetConfigNoCopy()
+	if err != nil {
+		return "", err
+	}
+	return config.ImageCopyTmpDir()
 }
 
 func KubePlay(w http.ResponseWriter, r *http.Request) {
+	runtime := r.Context().Value(api.RuntimeKey).(*libpod.Runtime)
+
 	// create a tmp directory
-	contextDirectory, err := os.MkdirTemp("", "libpod_kube")
+	tmpDir, err := kubeTmpDir(runtime)
 	if err != nil {
 		utils.InternalServerError(w, err)
 		return
 	}
-
-	// cleanup the tmp directory
-	defer func() {
-		err := os.RemoveAll(contextDirectory)
-		if err != nil {
-			logrus.Warn(fmt.Errorf("failed to remove libpod_kube tmp directory %q: %w", contextDirectory, err))
-		}
-	}()
-
-	// extract the reader
-	reader, err := extractPlayReader(contextDirectory, r)
+	contextDirectory, err := os.MkdirTemp(tmpDir, "libpod_kube")
 	if err != nil {
 		utils.InternalServerError(w, err)
 		return
 	}
 
-	runtime := r.Context().Value(api.RuntimeKey).(*libpod.Runtime)
+	// cleanup the tmp directory, once done playing it is removed before
+	// answering so that a failure can be reported to the client
+	defer removeContextDir(contextDirectory)
+
 	decoder := r.Context().Value(api.DecoderKey).(*schema.Decoder)
 	query := struct {
 		Annotations      map[string]string `schema:"annotations"`
@@ -124,9 +619,45 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		Wait             bool              `schema:"wait"`
 		Build            bool              `schema:"build"`
 		NoPodPrefix      bool              `schema:"noPodPrefix"`
+		MaxContextSize   int64             `schema:"maxContextSize"`
+		Stream           bool              `schema:"stream"`
+		DryRun           bool              `schema:"dryRun"`
+		Namespace        string            `schema:"namespace"`
+		PullPolicy       string            `schema:"pullPolicy"`
+		CPULimit         string            `schema:"cpuLimit"`
+		MemoryLimit      string            `schema:"memoryLimit"`
+		Retry            uint              `schema:"retry"`
+		RetryDelay       string            `schema:"retryDelay"`
+		NamePrefix       string            `schema:"namePrefix"`
+		BuildArgs        map[string]string `schema:"buildArgs"`
+		NoCache          bool              `schema:"noCache"`
+		BuildParallelism uint              `schema:"buildParallelism"`
+		Timeout          uint              `schema:"timeout"`
+		Atomic           bool              `schema:"atomic"`
+		AddHost          []string          `schema:"addHost"`
+		Restart          bool              `schema:"restart"`
+		LogLevel         string            `schema:"logLevel"`
+		Hostname         string            `schema:"hostname"`
+		Upload           string            `schema:"upload"`
+		Quiet            bool              `schema:"quiet"`
+		SkipExisting     bool              `schema:"skipExisting"`
+		AllowPrivileged  bool              `schema:"allowPrivileged"`
+		RequireDigest    bool              `schema:"requireDigest"`
+		WaitReady        bool              `schema:"waitReady"`
+		EnvFile          string            `schema:"envFile"`
+		CgroupParent     string            `schema:"cgroupParent"`
+		Sysctl           []string          `schema:"sysctl"`
+		DNSServer        []string          `schema:"dnsServer"`
+		DNSSearch        []string          `schema:"dnsSearch"`
+		DNSOption        []string          `schema:"dnsOption"`
+		Validate         string            `schema:"validate"`
+		Mirror           string            `schema:"mirror"`
 	}{
-		TLSVerify: true,
-		Start:     true,
+		TLSVerify:        true,
+		Start:            true,
+		BuildParallelism: 1,
+		Quiet:            true,
+		AllowPrivileged:  true,
 	}
 
 	if err := decoder.Decode(&query, r.URL.Query()); err != nil {
@@ -134,6 +665,127 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logLevel := query.LogLevel
+	if logLevel == "" {
+		logLevel = r.Header.Get(logLevelHeader)
+	}
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	if logLevel != "" {
+		level, err := logrus.ParseLevel(logLevel)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid log level %q: %w", logLevel, err))
+			return
+		}
+		logger = requestLogger(level)
+	}
+
+	// extract the reader, from the context uploaded in chunks if any
+	var reader io.Reader
+	if query.Upload != "" {
+		reader, err = extractUploadedPlayTar(contextDirectory, query.Upload, query.MaxContextSize, r.Header.Get(contextDigestHeader))
+	} else {
+		reader, err = extractPlayReader(contextDirectory, r, query.MaxContextSize)
+	}
+	if err != nil {
+		if errors.Is(err, errNoSuchUpload) {
+			utils.Error(w, http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, errContextTooLarge) {
+			utils.Error(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		if errors.Is(err, errContextDigestMismatch) {
+			utils.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		utils.InternalServerError(w, err)
+		return
+	}
+	logger.Debugf("Extracted the kube play context to %s", contextDirectory)
+
+	var env map[string]string
+	if query.EnvFile != "" {
+		env, err = readContextEnvFile(contextDirectory, query.EnvFile)
+		if err != nil {
+			if errors.Is(err, errInvalidEnvFile) {
+				utils.Error(w, http.StatusBadRequest, err)
+				return
+			}
+			utils.InternalServerError(w, err)
+			return
+		}
+	}
+
+	if err := validatePublishPorts(query.PublishPorts); err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	allowedDigests, err := parseAllowedDigests(r.Header.Get(allowedDigestsHeader))
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validateAddHosts(query.AddHost); err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(query.AddHost) > 0 && query.NoHosts {
+		utils.Error(w, http.StatusBadRequest, errors.New("addHost and noHosts are mutually exclusive"))
+		return
+	}
+
+	if err := validatePullPolicy(query.PullPolicy); err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validateRegistryMirror(query.Mirror); err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if query.Validate != "" && query.Validate != "strict" {
+		utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid validate %q: must be strict", query.Validate))
+		return
+	}
+
+	if _, err := util.ValidateSysctls(query.Sysctl); err != nil {
+		utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid sysctl: %w", err))
+		return
+	}
+
+	if _, found := r.URL.Query()["cgroupParent"]; found {
+		if err := validateCgroupParent(query.CgroupParent); err != nil {
+			utils.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	for param, value := range map[string]string{"cpuLimit": query.CPULimit, "memoryLimit": query.MemoryLimit} {
+		if value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(value); err != nil {
+			utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid %s %q: %w", param, value, err))
+			return
+		}
+	}
+
+	if err := annotations.ValidateAnnotations(query.Annotations); err != nil {
+		utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid annotations: %w", err))
+		return
+	}
+
+	if query.RetryDelay != "" {
+		if _, err := time.ParseDuration(query.RetryDelay); err != nil {
+			utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid retryDelay %q: %w", query.RetryDelay, err))
+			return
+		}
+	}
+
 	staticIPs := make([]net.IP, 0, len(query.StaticIPs))
 	for _, ipString := range query.StaticIPs {
 		ip := net.ParseIP(ipString)
@@ -144,6 +796,16 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		staticIPs = append(staticIPs, ip)
 	}
 
+	dnsServers := make([]net.IP, 0, len(query.DNSServer))
+	for _, server := range query.DNSServer {
+		ip := net.ParseIP(server)
+		if ip == nil {
+			utils.Error(w, http.StatusBadRequest, fmt.Errorf("invalid dnsServer %q: must be an IP address", server))
+			return
+		}
+		dnsServers = append(dnsServers, ip)
+	}
+
 	staticMACs := make([]net.HardwareAddr, 0, len(query.StaticMACs))
 	for _, macString := range query.StaticMACs {
 		mac, err := net.ParseMAC(macString)
@@ -154,6 +816,43 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		staticMACs = append(staticMACs, mac)
 	}
 
+	// The images of the context are only loaded once the request is valid, a
+	// dry run leaves the local storage as is.
+	if !query.DryRun {
+		if err := loadContextImages(r.Context(), logger, runtime.LibimageRuntime(), contextDirectory, query.Replace); err != nil {
+			utils.InternalServerError(w, err)
+			return
+		}
+	}
+
+	loadOCIImage := func(ref string) (string, error) {
+		return loadContextOCIImage(r.Context(), logger, runtime.LibimageRuntime(), contextDirectory, ref)
+	}
+	if query.DryRun {
+		// Only check the layouts, the images keep their oci: reference.
+		loadOCIImage = func(ref string) (string, error) {
+			if _, _, err := contextOCILayout(contextDirectory, ref); err != nil {
+				return "", err
+			}
+			return "oci:" + ref, nil
+		}
+	}
+	kubeYAML, err := io.ReadAll(reader)
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	kubeYAML, err = resolveContextOCIImages(kubeYAML, loadOCIImage)
+	if err != nil {
+		if errors.Is(err, errInvalidContextImage) {
+			utils.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		utils.InternalServerError(w, err)
+		return
+	}
+	reader = bytes.NewReader(kubeYAML)
+
 	authConf, authfile, err := auth.GetCredentials(r)
 	if err != nil {
 		utils.Error(w, http.StatusBadRequest, err)
@@ -181,6 +880,7 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		Annotations:        query.Annotations,
 		Authfile:           authfile,
 		IsRemote:           true,
+		Logger:             logger,
 		LogDriver:          logDriver,
 		LogOptions:         query.LogOptions,
 		Networks:           query.Network,
@@ -189,7 +889,7 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		Password:           password,
 		PublishPorts:       query.PublishPorts,
 		PublishAllPorts:    query.PublishAllPorts,
-		Quiet:              true,
+		Quiet:              query.Quiet,
 		Replace:            query.Replace,
 		ServiceContainer:   query.ServiceContainer,
 		StaticIPs:          staticIPs,
@@ -200,6 +900,37 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 		Wait:               query.Wait,
 		ContextDir:         contextDirectory,
 		NoPodPrefix:        query.NoPodPrefix,
+		DryRun:             query.DryRun,
+		Namespace:          query.Namespace,
+		PullPolicy:         query.PullPolicy,
+		CPULimit:           query.CPULimit,
+		MemoryLimit:        query.MemoryLimit,
+		RetryDelay:         query.RetryDelay,
+		NamePrefix:         query.NamePrefix,
+		BuildArgs:          query.BuildArgs,
+		NoCache:            query.NoCache,
+		BuildParallelism:   query.BuildParallelism,
+		Timeout:            query.Timeout,
+		Atomic:             query.Atomic,
+		AddHost:            query.AddHost,
+		Restart:            query.Restart,
+		Hostname:           query.Hostname,
+		SkipExisting:       query.SkipExisting,
+		DenyPrivileged:     !query.AllowPrivileged,
+		RequireDigest:      query.RequireDigest,
+		AllowedDigests:     allowedDigests,
+		WaitReady:          query.WaitReady,
+		Env:                env,
+		CgroupParent:       query.CgroupParent,
+		Sysctl:             query.Sysctl,
+		DNSServers:         dnsServers,
+		DNSSearch:          query.DNSSearch,
+		DNSOptions:         query.DNSOption,
+		StrictValidation:   query.Validate == "strict",
+		RegistryMirror:     query.Mirror,
+	}
+	if _, found := r.URL.Query()["retry"]; found {
+		options.Retry = &query.Retry
 	}
 	if _, found := r.URL.Query()["build"]; found {
 		options.Build = types.NewOptionalBool(query.Build)
@@ -210,19 +941,115 @@ func KubePlay(w http.ResponseWriter, r *http.Request) {
 	if _, found := r.URL.Query()["start"]; found {
 		options.Start = types.NewOptionalBool(query.Start)
 	}
-	report, err := containerEngine.PlayKube(r.Context(), reader, options)
+	// the progress is streamed unless explicitly asked to be quiet
+	if _, found := r.URL.Query()["quiet"]; !found && query.Stream {
+		options.Quiet = false
+	}
+	ctx := r.Context()
+	if query.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(query.Timeout)*time.Second)
+		defer cancel()
+	}
+	if query.Stream {
+		streamKubePlay(ctx, w, &containerEngine, reader, options)
+		return
+	}
+	report, err := containerEngine.PlayKube(ctx, reader, options)
+	cleanupWarning := removeContextDir(contextDirectory)
+	if cleanupWarning != "" {
+		w.Header().Set(cleanupWarningHeader, cleanupWarning)
+		if report != nil {
+			report.Warnings = append(report.Warnings, cleanupWarning)
+		}
+	}
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			// Let the client know what was created before giving up so
+			// it can clean up or retry.
+			if report == nil {
+				report = &entities.PlayKubeReport{}
+				if cleanupWarning != "" {
+					report.Warnings = append(report.Warnings, cleanupWarning)
+				}
+			}
+			report.Warnings = append(report.Warnings, fmt.Sprintf("kube play timed out after %ds: %v", query.Timeout, err))
+			utils.WriteResponse(w, http.StatusGatewayTimeout, report)
+			return
+		}
+		if errors.Is(err, abi.ErrPrivilegedNotAllowed) || errors.Is(err, abi.ErrImageNotAllowed) || errors.Is(err, abi.ErrSysctlConflict) || errors.Is(err, abi.ErrInvalidKubeYAML) {
+			utils.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		if report != nil && report.RolledBack {
+			err = fmt.Errorf("%w: rolled back the pods, volumes and secrets created by the request", err)
+		}
 		utils.Error(w, http.StatusInternalServerError, fmt.Errorf("playing YAML file: %w", err))
 		return
 	}
 	utils.WriteResponse(w, http.StatusOK, report)
 }
 
+// streamKubePlay plays the kube YAML in the background and streams the image
+// pull and build progress, unless quiet, to the client using the same line-delimited JSON
+// messages as the build endpoint. The last message either carries the error
+// or the final KubePlayReport in its aux field.
+func streamKubePlay(ctx context.Context, w http.ResponseWriter, containerEngine *abi.ContainerEngine, reader io.Reader, options entities.PlayKubeOptions) {
+	stdout := channel.NewWriter(make(chan []byte))
+	defer stdout.Close()
+	if !options.Quiet {
+		options.Writer = stdout
+	}
+
+	var (
+		report  *entities.PlayKubeReport
+		playErr error
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		report, playErr = containerEngine.PlayKube(ctx, reader, options)
+	}()
+
+	// Send headers and prime client for stream to come
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	sender := utils.NewBuildResponseSender(w)
+	for {
+		select {
+		case e := <-stdout.Chan():
+			sender.SendBuildStream(string(e))
+		case <-done:
+			// The headers are already sent, only the report can carry
+			// the warning.
+			if warning := removeContextDir(options.ContextDir); warning != "" && report != nil {
+				report.Warnings = append(report.Warnings, warning)
+			}
+			if playErr != nil {
+				sender.SendBuildError(fmt.Sprintf("playing YAML file: %v", playErr))
+				return
+			}
+			aux, err := json.Marshal(report)
+			if err != nil {
+				sender.SendBuildError(err.Error())
+				return
+			}
+			sender.SendBuildAux(aux)
+			return
+		}
+	}
+}
+
 func KubePlayDown(w http.ResponseWriter, r *http.Request) {
 	runtime := r.Context().Value(api.RuntimeKey).(*libpod.Runtime)
 	decoder := r.Context().Value(api.DecoderKey).(*schema.Decoder)
 	query := struct {
-		Force bool `schema:"force"`
+		Force         bool   `schema:"force"`
+		Timeout       uint   `schema:"timeout"`
+		KeepVolumes   bool   `schema:"keepVolumes"`
+		LabelSelector string `schema:"labelSelector"`
+		Namespace     string `schema:"namespace"`
 	}{
 		Force: false,
 	}
@@ -233,7 +1060,7 @@ func KubePlayDown(w http.ResponseWriter, r *http.Request) {
 	}
 
 	containerEngine := abi.ContainerEngine{Libpod: runtime}
-	report, err := containerEngine.PlayKubeDown(r.Context(), r.Body, entities.PlayKubeDownOptions{Force: query.Force})
+	report, err := containerEngine.PlayKubeDown(r.Context(), r.Body, entities.PlayKubeDownOptions{Force: query.Force, Timeout: query.Timeout, KeepVolumes: query.KeepVolumes, LabelSelector: query.LabelSelector, Namespace: query.Namespace})
 	if err != nil {
 		utils.Error(w, http.StatusInternalServerError, fmt.Errorf("tearing down YAML file: %w", err))
 		return
@@ -249,9 +1076,11 @@ func KubeApply(w http.ResponseWriter, r *http.Request) {
 	runtime := r.Context().Value(api.RuntimeKey).(*libpod.Runtime)
 	decoder := r.Context().Value(api.DecoderKey).(*schema.Decoder)
 	query := struct {
-		CACertFile string `schema:"caCertFile"`
-		Kubeconfig string `schema:"kubeconfig"`
-		Namespace  string `schema:"namespace"`
+		CACertFile      string `schema:"caCertFile"`
+		Kubeconfig      string `schema:"kubeconfig"`
+		Namespace       string `schema:"namespace"`
+		ServerSideApply bool   `schema:"serverSideApply"`
+		FieldManager    string `schema:"fieldManager"`
 	}{
 		// Defaults would go here.
 	}
@@ -261,12 +1090,45 @@ func KubeApply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := checkApplyFiles(query.Kubeconfig, query.CACertFile); err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
 	containerEngine := abi.ContainerEngine{Libpod: runtime}
-	options := entities.ApplyOptions{CACertFile: query.CACertFile, Kubeconfig: query.Kubeconfig, Namespace: query.Namespace}
-	if err := containerEngine.KubeApply(r.Context(), r.Body, options); err != nil {
+	options := entities.ApplyOptions{
+		CACertFile:      query.CACertFile,
+		Kubeconfig:      query.Kubeconfig,
+		Namespace:       query.Namespace,
+		ServerSideApply: query.ServerSideApply,
+		FieldManager:    query.FieldManager,
+	}
+	report, err := containerEngine.KubeApply(r.Context(), r.Body, options)
+	if err != nil {
+		if errors.Is(err, define.ErrKubeApplyConflict) {
+			utils.Error(w, http.StatusConflict, err)
+			return
+		}
 		utils.Error(w, http.StatusInternalServerError, fmt.Errorf("error applying YAML to k8s cluster: %w", err))
 		return
 	}
 
-	utils.WriteResponse(w, http.StatusOK, "Deployed!")
+	utils.WriteResponse(w, http.StatusOK, report)
+}
+
+// checkApplyFiles makes sure the kubeconfig and CA cert files given to kube
+// apply can be read. A CA cert file of "insecure" disables TLS verification
+// and is not a path.
+func checkApplyFiles(kubeconfig, caCertFile string) error {
+	if kubeconfig != "" {
+		if _, err := os.Stat(kubeconfig); err != nil {
+			return fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+	}
+	if caCertFile != "" && !strings.EqualFold(caCertFile, "insecure") {
+		if _, err := os.Stat(caCertFile); err != nil {
+			return fmt.Errorf("invalid CA cert file: %w", err)
+		}
+	}
+	return nil
 }
@@ -3,14 +3,48 @@
 package libpod
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
+// playTar returns a tar archive holding content as play.yaml, and the archive
+// compressed with gzip.
+func playTar(t *testing.T, content []byte) ([]byte, []byte) {
+	t.Helper()
+	var plain bytes.Buffer
+	tw := tar.NewWriter(&plain)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "play.yaml", Mode: 0o600, Size: int64(len(content))}))
+	_, err := tw.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, err = gw.Write(plain.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, gw.Close())
+	return plain.Bytes(), compressed.Bytes()
+}
+
 func TestExtractPlayReader(t *testing.T) {
 	// Setup temporary directory for testing purposes
 	tempDir := t.TempDir()
@@ -20,7 +54,7 @@ func TestExtractPlayReader(t *testing.T) {
 			Body: io.NopCloser(strings.NewReader("test body content")),
 		}
 
-		reader, err := extractPlayReader(tempDir, req)
+		reader, err := extractPlayReader(tempDir, req, 0)
 		assert.NoError(t, err)
 
 		// Read from the returned reader
@@ -45,7 +79,7 @@ func TestExtractPlayReader(t *testing.T) {
 				Body: io.NopCloser(strings.NewReader("test body content")),
 			}
 
-			reader, err := extractPlayReader(tempDir, req)
+			reader, err := extractPlayReader(tempDir, req, 0)
 			assert.NoError(t, err)
 
 			// Read from the returned reader
@@ -63,8 +97,443 @@ func TestExtractPlayReader(t *testing.T) {
 			Body: io.NopCloser(strings.NewReader("test body content")),
 		}
 
-		_, err := extractPlayReader(tempDir, req)
+		_, err := extractPlayReader(tempDir, req, 0)
 		assert.Error(t, err)
 		assert.Equal(t, "Content-Type: application/unsupported is not supported. Should be \"application/x-tar\"", err.Error())
 	})
+
+	t.Run("Tar content exceeding maxContextSize - should return error", func(t *testing.T) {
+		content := []byte(strings.Repeat("a", 4096))
+		archive, _ := playTar(t, content)
+
+		newRequest := func() *http.Request {
+			return &http.Request{
+				Header: map[string][]string{
+					"Content-Type": {"application/x-tar"},
+				},
+				Body: io.NopCloser(bytes.NewReader(archive)),
+			}
+		}
+
+		_, err := extractPlayReader(t.TempDir(), newRequest(), 1024)
+		assert.ErrorIs(t, err, errContextTooLarge)
+
+		reader, err := extractPlayReader(t.TempDir(), newRequest(), int64(len(archive)))
+		assert.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, content, data)
+	})
+
+	t.Run("Plain and gzip compressed tar content - should return play.yaml", func(t *testing.T) {
+		content := []byte("kind: Pod\n")
+		plain, compressed := playTar(t, content)
+
+		for _, body := range [][]byte{plain, compressed} {
+			for _, maxSize := range []int64{0, 1 << 20} {
+				// without Content-Type the tar is sniffed
+				for _, header := range []http.Header{{"Content-Type": {"application/x-tar"}}, {}} {
+					req := &http.Request{
+						Header: header,
+						Body:   io.NopCloser(bytes.NewReader(body)),
+					}
+					reader, err := extractPlayReader(t.TempDir(), req, maxSize)
+					assert.NoError(t, err)
+					data, err := io.ReadAll(reader)
+					assert.NoError(t, err)
+					assert.Equal(t, content, data)
+				}
+			}
+		}
+	})
+
+	t.Run("Tar declaring a length over the maximum size - should fail before extraction", func(t *testing.T) {
+		content := []byte("kind: Pod\n")
+		plain, compressed := playTar(t, content)
+
+		for _, body := range [][]byte{plain, compressed} {
+			// without Content-Type the tar is sniffed
+			for _, header := range []http.Header{{"Content-Type": {"application/x-tar; charset=binary"}}, {}} {
+				req := &http.Request{
+					Header:        header,
+					Body:          io.NopCloser(bytes.NewReader(body)),
+					ContentLength: 1 << 20,
+				}
+				_, err := extractPlayReader(t.TempDir(), req, 1024)
+				assert.ErrorIs(t, err, errContextTooLarge)
+			}
+		}
+
+		// YAML bodies are not extracted
+		req := &http.Request{
+			Header:        http.Header{"Content-Type": {"application/yaml"}},
+			Body:          io.NopCloser(bytes.NewReader(content)),
+			ContentLength: 1 << 20,
+		}
+		_, err := extractPlayReader(t.TempDir(), req, 1024)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Content-Type not provided and YAML larger than the sniffed bytes - should return body", func(t *testing.T) {
+		content := "kind: Pod\n" + strings.Repeat("# comment\n", 100)
+		req := &http.Request{
+			Body: io.NopCloser(strings.NewReader(content)),
+		}
+
+		reader, err := extractPlayReader(tempDir, req, 0)
+		assert.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+}
+
+func TestExtractTarFileDigest(t *testing.T) {
+	archive, _ := playTar(t, []byte("kind: Pod\n"))
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+
+	assert.NoError(t, extractTarFile(t.TempDir(), bytes.NewReader(archive), 0, digest))
+	assert.NoError(t, extractTarFile(t.TempDir(), bytes.NewReader(archive), 1024, strings.ToUpper(digest)))
+
+	corrupted := bytes.Clone(archive)
+	corrupted[512] = 'K' // first byte of the play.yaml content
+	err := extractTarFile(t.TempDir(), bytes.NewReader(corrupted), 0, digest)
+	assert.ErrorIs(t, err, errContextDigestMismatch)
+
+	err = extractTarFile(t.TempDir(), bytes.NewReader(archive), 0, "abc")
+	assert.ErrorIs(t, err, errContextDigestMismatch)
+}
+
+func TestResolveContextOCIImages(t *testing.T) {
+	kubeYAML := `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: oci:images/app:v1
+  - name: sidecar
+    image: "oci:images/sidecar"
+  - name: again
+    image: oci:images/app:v1
+  - name: remote
+    image: quay.io/podman/hello
+`
+	var loads []string
+	resolved, err := resolveContextOCIImages([]byte(kubeYAML), func(ref string) (string, error) {
+		loads = append(loads, ref)
+		return "id-" + strings.ReplaceAll(ref, "/", "-"), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"images/app:v1", "images/sidecar"}, loads)
+	assert.Equal(t, `apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: id-images-app:v1
+  - name: sidecar
+    image: "id-images-sidecar"
+  - name: again
+    image: id-images-app:v1
+  - name: remote
+    image: quay.io/podman/hello
+`, string(resolved))
+
+	_, err = resolveContextOCIImages([]byte(kubeYAML), func(ref string) (string, error) {
+		return loadContextOCIImage(context.Background(), logrus.NewEntry(logrus.StandardLogger()), nil, t.TempDir(), ref)
+	})
+	assert.ErrorIs(t, err, errInvalidContextImage)
+
+	for _, ref := range []string{"../outside", "/abs/path:v1"} {
+		_, err := loadContextOCIImage(context.Background(), logrus.NewEntry(logrus.StandardLogger()), nil, t.TempDir(), ref)
+		assert.ErrorIs(t, err, errInvalidContextImage, ref)
+	}
+
+	contextDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(contextDir, "images", "app"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(contextDir, "images", "app", "index.json"), []byte("{}"), 0o644))
+	path, image, err := contextOCILayout(contextDir, "images/app:v1")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(contextDir, "images", "app"), path)
+	assert.Equal(t, "v1", image)
+
+	// A symlinked directory may not point the layout out of the context.
+	outside := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(outside, "app"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "app", "index.json"), []byte("{}"), 0o644))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(contextDir, "link")))
+	for _, ref := range []string{"link/app:v1", "link/app"} {
+		_, _, err := contextOCILayout(contextDir, ref)
+		assert.ErrorIs(t, err, errInvalidContextImage, ref)
+	}
+}
+
+func TestParseAllowedDigests(t *testing.T) {
+	allowed, err := parseAllowedDigests("")
+	assert.NoError(t, err)
+	assert.Nil(t, allowed)
+
+	dgst := "sha256:" + strings.Repeat("a", 64)
+	allowed, err = parseAllowedDigests(`{"quay.io/app:1":"` + dgst + `"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"quay.io/app:1": dgst}, allowed)
+
+	_, err = parseAllowedDigests(`["quay.io/app:1"]`)
+	assert.Error(t, err)
+	_, err = parseAllowedDigests(`{"quay.io/app:1":"sha256:abc"}`)
+	assert.ErrorContains(t, err, "quay.io/app:1")
+}
+
+func TestCheckDeclaredContextSize(t *testing.T) {
+	newRequest := func(contentType string, length int64) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/libpod/kube/play", nil)
+		r.Header.Set("Content-Type", contentType)
+		r.ContentLength = length
+		return r
+	}
+
+	assert.ErrorIs(t, checkDeclaredContextSize(newRequest("application/x-tar", 2048), 1024), errContextTooLarge)
+	assert.NoError(t, checkDeclaredContextSize(newRequest("application/x-tar", 1024), 1024))
+	// chunked requests are bounded while extracted
+	assert.NoError(t, checkDeclaredContextSize(newRequest("application/x-tar", -1), 1024))
+	assert.NoError(t, checkDeclaredContextSize(newRequest("application/x-tar", 2048), 0))
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, end, total, err := parseContentRange("bytes 0-1023/4096")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0, 1023, 4096}, []int64{start, end, total})
+
+	start, end, total, err = parseContentRange("bytes 1024-2047/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1024, 2047, -1}, []int64{start, end, total})
+
+	for _, header := range []string{"", "bytes 0-1023", "bytes 10-5/*", "bytes 0-4096/4096", "items 0-1/2"} {
+		_, _, _, err := parseContentRange(header)
+		assert.Error(t, err, header)
+	}
+}
+
+func TestKubePlayUploadChunk(t *testing.T) {
+	uploadsDir = t.TempDir()
+
+	put := func(id, contentRange, body string) (int, entitiesTypes.KubePlayUploadReport) {
+		req := httptest.NewRequest(http.MethodPut, "/libpod/kube/play/uploads/"+id, strings.NewReader(body))
+		req.Header.Set("Content-Range", contentRange)
+		req = mux.SetURLVars(req, map[string]string{"name": id})
+		w := httptest.NewRecorder()
+		KubePlayUploadChunk(w, req)
+		var report entitiesTypes.KubePlayUploadReport
+		_ = json.NewDecoder(w.Body).Decode(&report)
+		return w.Code, report
+	}
+
+	w := httptest.NewRecorder()
+	KubePlayUploadCreate(w, httptest.NewRequest(http.MethodPost, "/libpod/kube/play/uploads", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created entitiesTypes.KubePlayUploadReport
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	id := created.ID
+
+	code, report := put(id, "bytes 0-4/*", "hello")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(5), report.Size)
+
+	// a chunk cut short keeps the bytes received
+	code, _ = put(id, "bytes 5-10/*", " wor")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// resending the whole chunk is refused with the offset to resume from
+	code, report = put(id, "bytes 5-10/*", " world")
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, code)
+	assert.Equal(t, int64(9), report.Size)
+
+	code, report = put(id, "bytes 9-10/11", "ld")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(11), report.Size)
+
+	path, err := uploadPath(id)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+
+	code, _ = put(id, fmt.Sprintf("bytes 11-%d/*", maxUploadSize), "x")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	code, _ = put(id, fmt.Sprintf("bytes 11-11/%d", maxUploadSize+1), "x")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	code, _ = put(strings.Repeat("0", 64), "bytes 0-0/*", "x")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = put("../etc", "bytes 0-0/*", "x")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	assert.Empty(t, uploadLocks)
+}
+
+func TestRemoveExpiredUploads(t *testing.T) {
+	dir := t.TempDir()
+	expired := filepath.Join(dir, strings.Repeat("a", 64)+".tar")
+	recent := filepath.Join(dir, strings.Repeat("b", 64)+".tar")
+	for _, path := range []string{expired, recent} {
+		assert.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+	}
+	old := time.Now().Add(-2 * uploadExpiry)
+	assert.NoError(t, os.Chtimes(expired, old, old))
+
+	removeExpiredUploads(dir, time.Now().Add(-uploadExpiry))
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, recent)
+	assert.Empty(t, uploadLocks)
+
+	// a missing directory has no uploads
+	removeExpiredUploads(filepath.Join(dir, "missing"), time.Now())
+}
+
+func TestValidatePublishPorts(t *testing.T) {
+	valid := []string{
+		"80",
+		"8080:80",
+		"8080:80/udp",
+		"127.0.0.1:8080:80/tcp",
+		"[::1]:8080:80",
+		"8000-8005:8000-8005",
+		":80",
+		"53:53/tcp,udp",
+	}
+	for _, port := range valid {
+		assert.NoError(t, validatePublishPorts([]string{port}), port)
+	}
+
+	invalid := []string{
+		"8080:80:foo",
+		"8080:80/foo",
+		"8000-8005:8000-8004",
+		"abc",
+		"8080:",
+	}
+	for _, port := range invalid {
+		err := validatePublishPorts([]string{"80", port})
+		assert.ErrorContains(t, err, port)
+	}
+}
+
+func TestValidateAddHosts(t *testing.T) {
+	valid := []string{
+		"db.local:10.0.0.5",
+		"a;b:192.168.1.1",
+		"ipv6:fd00::1",
+		"gw:host-gateway",
+	}
+	assert.NoError(t, validateAddHosts(valid))
+
+	invalid := []string{
+		"db.local",
+		":10.0.0.5",
+		"a;;b:10.0.0.5",
+		"db.local:10.0.0",
+		"db.local:",
+	}
+	for _, host := range invalid {
+		err := validateAddHosts([]string{"db.local:10.0.0.5", host})
+		assert.ErrorContains(t, err, host)
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	orig := logrus.GetLevel()
+	defer logrus.SetLevel(orig)
+	logrus.SetLevel(logrus.InfoLevel)
+
+	logger := requestLogger(logrus.DebugLevel)
+	assert.Equal(t, logrus.DebugLevel, logger.Logger.GetLevel())
+	// The level of the service is left untouched.
+	assert.Equal(t, logrus.InfoLevel, logrus.GetLevel())
+	// A less verbose level does not lower the level of the service.
+	assert.Equal(t, logrus.InfoLevel, requestLogger(logrus.WarnLevel).Logger.GetLevel())
+}
+
+func TestValidatePullPolicy(t *testing.T) {
+	for _, policy := range []string{"", "always", "missing", "never", "newer"} {
+		assert.NoError(t, validatePullPolicy(policy), policy)
+	}
+	assert.EqualError(t, validatePullPolicy("IfNotPresent"), `invalid pullPolicy "IfNotPresent": must be one of always, missing, never, newer`)
+}
+
+func TestValidateCgroupParent(t *testing.T) {
+	for _, parent := range []string{"machine.slice", "/kube/played"} {
+		assert.NoError(t, validateCgroupParent(parent), parent)
+	}
+	assert.EqualError(t, validateCgroupParent(""), "invalid cgroupParent: must not be empty")
+	assert.ErrorContains(t, validateCgroupParent("machine.slice\n"), "must not contain null or newline characters")
+	assert.ErrorContains(t, validateCgroupParent("machine\x00.slice"), "must not contain null or newline characters")
+}
+
+func TestValidateRegistryMirror(t *testing.T) {
+	for _, mirror := range []string{"", "mirror.example.com", "mirror.example.com:5000", "localhost:5000/dockerhub"} {
+		assert.NoError(t, validateRegistryMirror(mirror), mirror)
+	}
+	for _, mirror := range []string{"mirror", "https://mirror.example.com", "mirror.example.com/hub:v1", "Mirror.example.com/Hub"} {
+		assert.ErrorContains(t, validateRegistryMirror(mirror), "invalid mirror", mirror)
+	}
+}
+
+func TestCheckApplyFiles(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	assert.NoError(t, os.WriteFile(kubeconfig, nil, 0o600))
+	missing := filepath.Join(dir, "missing")
+
+	assert.NoError(t, checkApplyFiles("", ""))
+	assert.NoError(t, checkApplyFiles(kubeconfig, "insecure"))
+	assert.NoError(t, checkApplyFiles(kubeconfig, kubeconfig))
+	assert.ErrorContains(t, checkApplyFiles(missing, ""), "invalid kubeconfig: stat "+missing)
+	assert.ErrorContains(t, checkApplyFiles(kubeconfig, missing), "invalid CA cert file: stat "+missing)
+}
+
+func TestLoadContextImages(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, loadContextImages(context.Background(), logrus.NewEntry(logrus.StandardLogger()), nil, dir, false))
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "images"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "images", "foobar.tar"), []byte("not an archive"), 0o644))
+	assert.ErrorContains(t, loadContextImages(context.Background(), logrus.NewEntry(logrus.StandardLogger()), nil, dir, false), "loading image archive foobar.tar")
+}
+
+func TestReadContextEnvFile(t *testing.T) {
+	contextDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(contextDir, "app.env"), []byte("# common settings\nLEVEL=info\n\n  URL=http://db:5432/?sslmode=disable\nEMPTY=\n"), 0o600)
+	assert.NoError(t, err)
+	env, err := readContextEnvFile(contextDir, "app.env")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"LEVEL": "info", "URL": "http://db:5432/?sslmode=disable", "EMPTY": ""}, env)
+
+	err = os.WriteFile(filepath.Join(contextDir, "bad.env"), []byte("LEVEL=info\n# comment\nexport REGION=eu\n"), 0o600)
+	assert.NoError(t, err)
+	_, err = readContextEnvFile(contextDir, "bad.env")
+	assert.ErrorIs(t, err, errInvalidEnvFile)
+	assert.ErrorContains(t, err, "line 3")
+
+	_, err = readContextEnvFile(contextDir, "missing.env")
+	assert.ErrorIs(t, err, errInvalidEnvFile)
+	_, err = readContextEnvFile(contextDir, "../app.env")
+	assert.ErrorIs(t, err, errInvalidEnvFile)
+
+	assert.NoError(t, os.Symlink("/etc/passwd", filepath.Join(contextDir, "link.env")))
+	_, err = readContextEnvFile(contextDir, "link.env")
+	assert.ErrorIs(t, err, errInvalidEnvFile)
+
+	outside := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "app.env"), []byte("LEVEL=debug\n"), 0o600))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(contextDir, "dir")))
+	_, err = readContextEnvFile(contextDir, "dir/app.env")
+	assert.ErrorIs(t, err, errInvalidEnvFile)
+
+	// Links inside the context are followed.
+	assert.NoError(t, os.Mkdir(filepath.Join(contextDir, "config"), 0o755))
+	assert.NoError(t, os.Symlink("../app.env", filepath.Join(contextDir, "config", "app.env")))
+	env, err = readContextEnvFile(contextDir, "config/app.env")
+	assert.NoError(t, err)
+	assert.Equal(t, "info", env["LEVEL"])
 }
@@ -0,0 +1,311 @@
+//go:build !remote
+
+package libpod
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/podman/v5/libpod"
+	"github.com/containers/podman/v5/pkg/api/handlers/utils"
+	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
+	"github.com/sirupsen/logrus"
+	"go.podman.io/storage/pkg/stringid"
+)
+
+// errNoSuchUpload is returned for an unknown, or already played, upload.
+var errNoSuchUpload = errors.New("no such kube play upload")
+
+// contentRangeRegexp matches the Content-Range header of an upload chunk.
+var contentRangeRegexp = regexp.MustCompile(`^bytes (\d+)-(\d+)/(\d+|\*)$`)
+
+// errUploadTooLarge is returned for a chunk extending an upload past
+// maxUploadSize.
+var errUploadTooLarge = errors.New("kube play upload exceeds the maximum size")
+
+// maxUploadSize is the size of the largest context tar uploaded in chunks.
+const maxUploadSize = 16 << 30
+
+// uploadExpiry is how long an upload not written to is kept.
+const uploadExpiry = 24 * time.Hour
+
+// uploadLock serializes the requests writing to the same upload, it is
+// dropped once no request holds it.
+type uploadLock struct {
+	sync.Mutex
+	refs int
+}
+
+var (
+	// uploadLocksLock protects uploadLocks.
+	uploadLocksLock sync.Mutex
+	// uploadLocks holds the locks of the uploads requests are working on.
+	uploadLocks = make(map[string]*uploadLock)
+)
+
+// uploadsDir holds the context tars uploaded in chunks until they are
+// played, it is set by SetupKubePlayUploads.
+var uploadsDir string
+
+// SetupKubePlayUploads places the context tars uploaded in chunks in the
+// directory the play contexts are extracted in, and removes the uploads of a
+// previous run of the service that expired.
+func SetupKubePlayUploads(runtime *libpod.Runtime) error {
+	tmpDir, err := kubeTmpDir(runtime)
+	if err != nil {
+		return err
+	}
+	uploadsDir = filepath.Join(tmpDir, "libpod_kube_uploads")
+	removeExpiredUploads(uploadsDir, time.Now().Add(-uploadExpiry))
+	return nil
+}
+
+// removeExpiredUploads removes the uploads of dir not written to since
+// before.
+func removeExpiredUploads(dir string, before time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("Listing kube play uploads: %v", err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".tar")
+		unlock := lockUpload(id)
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("Removing expired kube play upload %s: %v", id, err)
+		}
+		unlock()
+	}
+}
+
+// uploadPath returns the path of the tar of the upload id, failing for IDs
+// that were not generated by KubePlayUploadCreate.
+func uploadPath(id string) (string, error) {
+	if err := stringid.ValidateID(id); err != nil {
+		return "", fmt.Errorf("%w: %s", errNoSuchUpload, id)
+	}
+	path := filepath.Join(uploadsDir, id+".tar")
+	if err := fileExists(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// fileExists returns errNoSuchUpload when the upload tar at path is missing.
+func fileExists(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", errNoSuchUpload, filepath.Base(path))
+		}
+		return err
+	}
+	return nil
+}
+
+// lockUpload locks the upload id and returns the function unlocking it.
+func lockUpload(id string) func() {
+	uploadLocksLock.Lock()
+	lock, ok := uploadLocks[id]
+	if !ok {
+		lock = new(uploadLock)
+		uploadLocks[id] = lock
+	}
+	lock.refs++
+	uploadLocksLock.Unlock()
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		uploadLocksLock.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(uploadLocks, id)
+		}
+		uploadLocksLock.Unlock()
+	}
+}
+
+// parseContentRange parses a "bytes start-end/total" Content-Range header,
+// total is -1 when unknown.
+func parseContentRange(header string) (start, end, total int64, err error) {
+	m := contentRangeRegexp.FindStringSubmatch(header)
+	if m == nil {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q: must be in the bytes start-end/total format", header)
+	}
+	start, _ = strconv.ParseInt(m[1], 10, 64)
+	end, _ = strconv.ParseInt(m[2], 10, 64)
+	total = -1
+	if m[3] != "*" {
+		total, _ = strconv.ParseInt(m[3], 10, 64)
+	}
+	if end < start || (total >= 0 && end >= total) {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return start, end, total, nil
+}
+
+// uploadReport returns the report of the upload id stored at path.
+func uploadReport(id, path string) (*entitiesTypes.KubePlayUploadReport, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &entitiesTypes.KubePlayUploadReport{ID: id, Size: info.Size()}, nil
+}
+
+// KubePlayUploadCreate starts the upload of a kube play context in chunks.
+// The expired uploads are removed first.
+func KubePlayUploadCreate(w http.ResponseWriter, _ *http.Request) {
+	removeExpiredUploads(uploadsDir, time.Now().Add(-uploadExpiry))
+	if err := os.MkdirAll(uploadsDir, 0o700); err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	id := stringid.GenerateRandomID()
+	f, err := os.OpenFile(filepath.Join(uploadsDir, id+".tar"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	if err := f.Close(); err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	utils.WriteResponse(w, http.StatusCreated, entitiesTypes.KubePlayUploadReport{ID: id})
+}
+
+// KubePlayUploadInspect reports how much of the context was received, the
+// next chunk must start at that offset.
+func KubePlayUploadInspect(w http.ResponseWriter, r *http.Request) {
+	id := utils.GetName(r)
+	path, err := uploadPath(id)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	report, err := uploadReport(id, path)
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	utils.WriteResponse(w, http.StatusOK, report)
+}
+
+// KubePlayUploadChunk appends the chunk of the body to the upload. A chunk
+// not starting where the upload ends is refused with the current size, so
+// the client can resume from it. The bytes of a chunk cut short are kept.
+// Uploads are limited to maxUploadSize bytes.
+func KubePlayUploadChunk(w http.ResponseWriter, r *http.Request) {
+	id := utils.GetName(r)
+	path, err := uploadPath(id)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	start, end, total, err := parseContentRange(r.Header.Get("Content-Range"))
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if end >= maxUploadSize || total > maxUploadSize {
+		utils.Error(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%w of %d bytes", errUploadTooLarge, maxUploadSize))
+		return
+	}
+
+	defer lockUpload(id)()
+	report, err := uploadReport(id, path)
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	if start != report.Size {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", report.Size-1))
+		utils.WriteResponse(w, http.StatusRequestedRangeNotSatisfiable, report)
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	n, copyErr := io.CopyN(f, r.Body, end-start+1)
+	closeErr := f.Close()
+	report.Size += n
+	if copyErr != nil {
+		utils.Error(w, http.StatusBadRequest, fmt.Errorf("receiving chunk %d-%d: %w", start, end, copyErr))
+		return
+	}
+	if closeErr != nil {
+		utils.InternalServerError(w, closeErr)
+		return
+	}
+	utils.WriteResponse(w, http.StatusOK, report)
+}
+
+// KubePlayUploadDelete abandons an upload.
+func KubePlayUploadDelete(w http.ResponseWriter, r *http.Request) {
+	id := utils.GetName(r)
+	path, err := uploadPath(id)
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+	unlock := lockUpload(id)
+	err = os.Remove(path)
+	unlock()
+	if err != nil {
+		utils.InternalServerError(w, err)
+		return
+	}
+	utils.WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// uploadError answers with 404 for unknown uploads and 500 otherwise.
+func uploadError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNoSuchUpload) {
+		utils.Error(w, http.StatusNotFound, err)
+		return
+	}
+	utils.InternalServerError(w, err)
+}
+
+// extractUploadedPlayTar extracts the context uploaded as id, see
+// extractPlayTar, and removes the upload once extracted.
+func extractUploadedPlayTar(anchorDir, id string, maxContextSize int64, digest string) (io.Reader, error) {
+	path, err := uploadPath(id)
+	if err != nil {
+		return nil, err
+	}
+	defer lockUpload(id)()
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && maxContextSize > 0 && info.Size() > maxContextSize {
+		return nil, fmt.Errorf("%w of %d bytes: the upload holds %d bytes", errContextTooLarge, maxContextSize, info.Size())
+	}
+	reader, err := extractPlayTar(anchorDir, f, maxContextSize, digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		logrus.Warnf("Removing kube play upload %s: %v", id, err)
+	}
+	return reader, nil
+}
@@ -80,6 +80,20 @@ type playKubeResponseLibpod struct {
 	Body entities.PlayKubeReport
 }
 
+// Kube play upload
+// swagger:response
+type kubePlayUploadResponseLibpod struct {
+	// in:body
+	Body entities.KubePlayUploadReport
+}
+
+// KubeApply response
+// swagger:response
+type kubeApplyResponseLibpod struct {
+	// in:body
+	Body entities.ApplyReport
+}
+
 // Image Delete
 // swagger:response
 type imageDeleteResponse struct {
@@ -28,6 +28,8 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//   #### Tar format
 	//
 	//   The tar format must contain a `play.yaml` file at the root that will be used.
+	//   It is extracted in the `image_copy_tmp_dir` of containers.conf, or `TMPDIR`, when set,
+	//   and in the default temporary directory otherwise.
 	//   If the file format requires context to build an image, it uses the image name and
 	//   check for corresponding folder.
 	//
@@ -52,16 +54,54 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//      image: foobar
 	//   ```
 	//
+	//   The tar may also contain an `images/` folder of OCI archives. They are loaded into the
+	//   local storage before the images of the `play.yaml` are looked up, which allows playing
+	//   without access to a registry. Archives of images already in the local storage are
+	//   skipped unless `replace` is set. They are only loaded once the request is validated,
+	//   and not on a `dryRun`.
+	//
+	//   Images of the `play.yaml` may also refer to an OCI layout directory of the tar with the
+	//   `oci:<relative-path>[:<reference>]` transport, the layout is loaded into the local storage
+	//   and the containers use the loaded image. The layout may not leave the tar through a
+	//   symlink. A `dryRun` only checks the layouts without loading them.
+	//
+	//   The uploaded context is removed once the YAML is played. When that fails, the
+	//   `X-Cleanup-Warning` response header and the `Warnings` of the report describe the
+	//   problem, the request itself still succeeds.
+	//
+	//   Large contexts can be uploaded in chunks to `/libpod/kube/play/uploads` beforehand and
+	//   played by passing the ID of the upload as the `upload` parameter, the body is then ignored.
+	//
 	// parameters:
 	//  - in: header
 	//    name: Content-Type
 	//    type: string
 	//    default: plain/text
 	//    enum: ["plain/text", "application/x-tar"]
+	//  - in: header
+	//    name: X-Allowed-Digests
+	//    type: string
+	//    description: JSON object mapping images, as written in the YAML, to the digest they must resolve to. An image resolving to another digest is refused with a 400.
+	//  - in: header
+	//    name: X-Registry-Auth
+	//    type: string
+	//    description: A base64-encoded auth configuration.
+	//  - in: header
+	//    name: X-Registry-Config
+	//    type: string
+	//    description: A base64-encoded map of registries to auth configurations, to authenticate to several registries.
+	//  - in: header
+	//    name: X-Context-SHA256
+	//    type: string
+	//    description: Hex encoded SHA-256 digest of the application/x-tar body, as sent. The request is rejected when the body does not match it.
+	//  - in: header
+	//    name: X-Podman-Log-Level
+	//    type: string
+	//    description: Log level of the messages of this request, see the logLevel query parameter.
 	//  - in: query
 	//    name: annotations
 	//    type: string
-	//    description: JSON encoded value of annotations (a map[string]string).
+	//    description: JSON encoded value of annotations (a map[string]string). Keys must be valid Kubernetes annotation keys. Values longer than 63 characters are reported as warnings unless noTrunc is set.
 	//  - in: query
 	//    name: logDriver
 	//    type: string
@@ -84,6 +124,12 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//    default: false
 	//    description: do not setup /etc/hosts file in container
 	//  - in: query
+	//    name: addHost
+	//    type: array
+	//    description: Add entries to the /etc/hosts file of the pods, in the host:ip format. The IP may be host-gateway. Conflicts with noHosts.
+	//    items:
+	//      type: string
+	//  - in: query
 	//    name: noTrunc
 	//    type: boolean
 	//    default: false
@@ -91,7 +137,7 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//  - in: query
 	//    name: publishPorts
 	//    type: array
-	//    description: publish a container's port, or a range of ports, to the host
+	//    description: publish a container's port, or a range of ports, to the host, using the [[ip:]hostPort[-endPort]:]containerPort[-endPort][/protocol] format
 	//    items:
 	//         type: string
 	//  - in: query
@@ -104,6 +150,24 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//    default: false
 	//    description: replace existing pods and containers
 	//  - in: query
+	//    name: hostname
+	//    type: string
+	//    description: Hostname of the pod, overriding the one of the YAML. The request fails when the YAML describes several pods.
+	//  - in: query
+	//    name: logLevel
+	//    type: string
+	//    enum: ["trace", "debug", "info", "warn", "warning", "error", "fatal", "panic"]
+	//    description: Raise the log level of the messages logged by the service while playing the YAML. Only the messages of this request are affected, the level of the service is left untouched. The messages logged by the image pulls and builds themselves keep the level of the service.
+	//  - in: query
+	//    name: restart
+	//    type: boolean
+	//    default: false
+	//    description: Restart the containers of the pods already played with the same spec and play options, and recreate the pods whose spec or options changed. Pods not played with restart before have an unknown spec, they are recreated with a warning in the Logs of the pod. The Action of each pod in the report tells whether it was created, recreated, restarted or left unchanged.
+	//  - in: query
+	//    name: quiet
+	//    type: boolean
+	//    description: Suppress the image pull and build progress. Defaults to true, or to false when streaming. Without stream, the progress is written to the standard error of the service.
+	//  - in: query
 	//    name: serviceContainer
 	//    type: boolean
 	//    default: false
@@ -140,9 +204,144 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//    default: false
 	//    description: Clean up all objects created when a SIGTERM is received or pods exit.
 	//  - in: query
+	//    name: waitReady
+	//    type: boolean
+	//    default: false
+	//    description: Wait for the started containers with a healthcheck to be healthy, and for the others to be running or to have exited successfully, before answering. Fails when a container turns unhealthy or exits with an error. Use timeout to bound the wait.
+	//  - in: query
+	//    name: mirror
+	//    type: string
+	//    description: Registry, optionally followed by a repository prefix, to pull the images of containers and image volumes from instead of the registry of their fully-qualified references. Only the registry is replaced, the repository, tag and digest are kept, and the pulled images are tagged with the name of the YAML. Short names, localhost images and images built from the context are left as is. The allowed digests apply to the images as written in the YAML.
+	//  - in: query
+	//    name: validate
+	//    type: string
+	//    enum: ["strict"]
+	//    description: With strict, the documents are checked against the schema of their kind before anything is played. An unknown field or a value of the wrong type is refused with a 400 naming the document and the field path.
+	//  - in: query
+	//    name: dnsServer
+	//    type: array
+	//    items:
+	//      type: string
+	//    description: IP addresses of the name servers of the pods, replacing those of the YAML. An entry that is not an IP address is refused with a 400.
+	//  - in: query
+	//    name: dnsSearch
+	//    type: array
+	//    items:
+	//      type: string
+	//    description: Search domains of the pods, replacing those of the YAML.
+	//  - in: query
+	//    name: dnsOption
+	//    type: array
+	//    items:
+	//      type: string
+	//    description: Resolver options of the pods, replacing those of the YAML.
+	//  - in: query
+	//    name: sysctl
+	//    type: array
+	//    items:
+	//      type: string
+	//    description: Kernel parameters, in the name=value format, set in every pod in addition to the sysctls of the YAML, replacing their values. An entry without =, or a network or IPC sysctl for a pod using the host network or IPC namespace, is refused with a 400.
+	//  - in: query
+	//    name: cgroupParent
+	//    type: string
+	//    description: Parent cgroup of the created pods and their containers. An empty value, or one with null or newline characters, is refused with a 400.
+	//  - in: query
+	//    name: envFile
+	//    type: string
+	//    description: Path of a file of the context tar holding KEY=VALUE lines, the variables are set in each container unless the YAML defines them. A missing file or a malformed line is refused with a 400 naming it.
+	//  - in: query
 	//    name: build
 	//    type: boolean
 	//    description: Build the images with corresponding context.
+	//  - in: query
+	//    name: maxContextSize
+	//    type: integer
+	//    format: int64
+	//    default: 0
+	//    description: Maximum size in bytes of the decompressed build context sent as a, possibly gzip compressed, tar, 0 means unlimited. A tar declaring a larger Content-Length is refused before the context is extracted.
+	//  - in: query
+	//    name: stream
+	//    type: boolean
+	//    default: false
+	//    description: |
+	//      Stream the image pull and build progress as line-delimited JSON objects, using the same format as the build endpoint.
+	//      The last object contains either the error or the final report in its `aux` field.
+	//  - in: query
+	//    name: dryRun
+	//    type: boolean
+	//    default: false
+	//    description: Validate the YAML and report the pods, containers, volumes and secrets that would be created by name, and the images that would be pulled or built, without creating, pulling, building or starting anything.
+	//  - in: query
+	//    name: namespace
+	//    type: string
+	//    description: Prefix the pod names, and so the names of their containers, with the namespace and label the pods with it. Only pods are namespaced, volumes, configmap volumes and secrets keep the names of the YAML and are shared with the other namespaces, the report then carries a warning.
+	//  - in: query
+	//    name: pullPolicy
+	//    type: string
+	//    enum: ["always", "missing", "never", "newer"]
+	//    description: Pull policy applied to all images, overriding the imagePullPolicy of the containers.
+	//  - in: query
+	//    name: cpuLimit
+	//    type: string
+	//    description: Maximum CPU limit of every container, as a Kubernetes quantity (e.g. 500m). Lower limits set in the YAML win.
+	//  - in: query
+	//    name: memoryLimit
+	//    type: string
+	//    description: Maximum memory limit of every container, as a Kubernetes quantity (e.g. 512Mi). Lower limits set in the YAML win.
+	//  - in: query
+	//    name: retry
+	//    type: integer
+	//    description: Number of times to retry pulling an image in case of a transient failure.
+	//  - in: query
+	//    name: retryDelay
+	//    type: string
+	//    description: Delay between pull retries (e.g. 5s). Retries use an exponential backoff when unset.
+	//  - in: query
+	//    name: namePrefix
+	//    type: string
+	//    description: Prefix prepended to the names of the pods, containers and volumes created.
+	//  - in: query
+	//    name: buildArgs
+	//    type: string
+	//    description: JSON encoded value of the build arguments (a map[string]string) used to build the images from the context directory. They apply to every image built, there is no per-image scoping.
+	//  - in: query
+	//    name: noCache
+	//    type: boolean
+	//    default: false
+	//    description: Do not use cached layers when building the images from the context directory.
+	//  - in: query
+	//    name: buildParallelism
+	//    type: integer
+	//    default: 1
+	//    description: Maximum number of images built at the same time from the context directory. When greater than 1, the images are built before creating the pods; images built from the same Containerfile are built one after the other.
+	//  - in: query
+	//    name: timeout
+	//    type: integer
+	//    description: Seconds after which the service gives up on the request. A report of what was created before giving up is returned with a 504 status. Zero means no timeout.
+	//  - in: query
+	//    name: atomic
+	//    type: boolean
+	//    default: false
+	//    description: Remove the pods, volumes and secrets created by the request when it fails. Pods removed because of replace are not restored.
+	//  - in: query
+	//    name: allowPrivileged
+	//    type: boolean
+	//    default: true
+	//    description: Allow the containers whose security context asks for privileges. When false, a YAML with privileged containers is refused with a 400 naming them.
+	//  - in: query
+	//    name: requireDigest
+	//    type: boolean
+	//    default: false
+	//    description: Refuse the YAML with a 400 naming the images that are not referenced by digest.
+	//  - in: query
+	//    name: skipExisting
+	//    type: boolean
+	//    default: false
+	//    description: Use the images present in the local storage without contacting a registry, whatever the pull policy. Missing images are still pulled.
+	//  - in: query
+	//    name: upload
+	//    type: string
+	//    description: ID of a context tar uploaded in chunks to play instead of the body. The upload is removed once extracted.
 	//  - in: body
 	//    name: request
 	//    description: Kubernetes YAML file.
@@ -153,23 +352,153 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	// responses:
 	//   200:
 	//     $ref: "#/responses/playKubeResponseLibpod"
+	//   400:
+	//     $ref: "#/responses/badParamError"
+	//   404:
+	//     description: no such upload
+	//   413:
+	//     description: the decompressed build context exceeds maxContextSize
 	//   500:
 	//     $ref: "#/responses/internalError"
+	//   504:
+	//     $ref: "#/responses/playKubeResponseLibpod"
 	r.HandleFunc(VersionedPath("/libpod/play/kube"), s.APIHandler(libpod.PlayKube)).Methods(http.MethodPost)
 	r.HandleFunc(VersionedPath("/libpod/kube/play"), s.APIHandler(libpod.KubePlay)).Methods(http.MethodPost)
+	// swagger:operation POST /libpod/kube/play/uploads libpod KubePlayUploadCreateLibpod
+	// ---
+	// tags:
+	//  - pods
+	// summary: Start a kube play context upload
+	// description: |
+	//   Start uploading a context tar in chunks, for contexts too large to be sent in a single request.
+	//   The chunks are sent to `/libpod/kube/play/uploads/{name}` and the upload is played by passing its ID
+	//   as the `upload` parameter of `/libpod/kube/play`. Uploads not written to for 24 hours are removed.
+	// produces:
+	// - application/json
+	// responses:
+	//   201:
+	//     $ref: "#/responses/kubePlayUploadResponseLibpod"
+	//   500:
+	//     $ref: "#/responses/internalError"
+	r.HandleFunc(VersionedPath("/libpod/kube/play/uploads"), s.APIHandler(libpod.KubePlayUploadCreate)).Methods(http.MethodPost)
+	// swagger:operation PUT /libpod/kube/play/uploads/{name} libpod KubePlayUploadChunkLibpod
+	// ---
+	// tags:
+	//  - pods
+	// summary: Upload a chunk of a kube play context
+	// description: |
+	//   Append the body to the upload. The chunk must start where the upload ends, otherwise it is refused
+	//   with a 416 status and the size received so far, from which the client resumes.
+	//   When the body is cut short, the bytes received are kept. Uploads are limited to 16 GiB.
+	// parameters:
+	//  - in: path
+	//    name: name
+	//    type: string
+	//    required: true
+	//    description: ID of the upload
+	//  - in: header
+	//    name: Content-Range
+	//    type: string
+	//    required: true
+	//    description: Range of the chunk in the context tar (e.g. bytes 0-1048575/4194304), the total may be `*`.
+	//  - in: body
+	//    name: request
+	//    description: Chunk of the context tar.
+	//    schema:
+	//      type: string
+	//      format: binary
+	// produces:
+	// - application/json
+	// responses:
+	//   200:
+	//     $ref: "#/responses/kubePlayUploadResponseLibpod"
+	//   400:
+	//     $ref: "#/responses/badParamError"
+	//   404:
+	//     description: no such upload
+	//   413:
+	//     description: the chunk extends the upload past the maximum size
+	//   416:
+	//     $ref: "#/responses/kubePlayUploadResponseLibpod"
+	//   500:
+	//     $ref: "#/responses/internalError"
+	r.HandleFunc(VersionedPath("/libpod/kube/play/uploads/{name}"), s.APIHandler(libpod.KubePlayUploadChunk)).Methods(http.MethodPut)
+	// swagger:operation GET /libpod/kube/play/uploads/{name} libpod KubePlayUploadInspectLibpod
+	// ---
+	// tags:
+	//  - pods
+	// summary: Inspect a kube play context upload
+	// description: Report the size received so far, where the next chunk must start.
+	// parameters:
+	//  - in: path
+	//    name: name
+	//    type: string
+	//    required: true
+	//    description: ID of the upload
+	// produces:
+	// - application/json
+	// responses:
+	//   200:
+	//     $ref: "#/responses/kubePlayUploadResponseLibpod"
+	//   404:
+	//     description: no such upload
+	//   500:
+	//     $ref: "#/responses/internalError"
+	r.HandleFunc(VersionedPath("/libpod/kube/play/uploads/{name}"), s.APIHandler(libpod.KubePlayUploadInspect)).Methods(http.MethodGet)
+	// swagger:operation DELETE /libpod/kube/play/uploads/{name} libpod KubePlayUploadDeleteLibpod
+	// ---
+	// tags:
+	//  - pods
+	// summary: Abandon a kube play context upload
+	// parameters:
+	//  - in: path
+	//    name: name
+	//    type: string
+	//    required: true
+	//    description: ID of the upload
+	// produces:
+	// - application/json
+	// responses:
+	//   204:
+	//     description: no error
+	//   404:
+	//     description: no such upload
+	//   500:
+	//     $ref: "#/responses/internalError"
+	r.HandleFunc(VersionedPath("/libpod/kube/play/uploads/{name}"), s.APIHandler(libpod.KubePlayUploadDelete)).Methods(http.MethodDelete)
 	// swagger:operation DELETE /libpod/play/kube libpod PlayKubeDownLibpod
 	// ---
 	// tags:
 	//  - containers
 	//  - pods
 	// summary: Remove resources created from kube play
-	// description: Tears down pods, secrets, and volumes defined in a YAML file
+	// description: |
+	//   Tears down pods, secrets, and volumes defined in a YAML file.
+	//   The outcome of each removal is listed in the resources of the report, the request only fails when none of them could be removed.
 	// parameters:
 	//  - in: query
 	//    name: force
 	//    type: boolean
 	//    default: false
 	//    description: Remove volumes.
+	//  - in: query
+	//    name: timeout
+	//    type: integer
+	//    default: 0
+	//    description: Seconds to wait for the containers to stop before killing them. 0 uses the stop timeout of each container.
+	//  - in: query
+	//    name: keepVolumes
+	//    type: boolean
+	//    default: false
+	//    description: Keep the volumes, even with force. The kept volumes are listed in the report.
+	//  - in: query
+	//    name: labelSelector
+	//    type: string
+	//    description: Only tear down the documents whose pods match this equality-based label selector (e.g. app=web,tier!=db). Other kinds are matched on their own labels. The skipped documents are listed in the report.
+	//  - in: query
+	//    name: namespace
+	//    type: string
+	//    description: Only tear down the pods played in this namespace. Volumes and secrets are not created per namespace and are kept, they are listed in the skipped documents of the report.
 	// produces:
 	// - application/json
 	// responses:
@@ -219,16 +548,48 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//    name: podmanOnly
 	//    type: boolean
 	//    default: false
-	//    description: add podman-only reserved annotations in generated YAML file (cannot be used by Kubernetes)
+	//    description: add podman-only reserved annotations in generated YAML file (cannot be used by Kubernetes). Container healthchecks are then not converted to liveness probes.
+	//  - in: query
+	//    name: networkPolicy
+	//    type: boolean
+	//    default: false
+	//    description: Generate a NetworkPolicy for each network the pods are attached to, only allowing traffic between these pods.
+	//  - in: query
+	//    name: format
+	//    type: string
+	//    enum: ["yaml", "jsonl"]
+	//    default: yaml
+	//    description: Output format. jsonl writes each generated object as a JSON document on its own line.
+	//  - in: query
+	//    name: split
+	//    type: boolean
+	//    default: false
+	//    description: Return a tar archive holding a <kind>-<name>.yaml file for each generated object. Only supported with the yaml format.
+	//  - in: query
+	//    name: pvc
+	//    type: boolean
+	//    default: false
+	//    description: Generate a PersistentVolumeClaim for each named volume used by the pods and containers.
+	//  - in: query
+	//    name: pvcStorageClass
+	//    type: string
+	//    description: Storage class of the generated PersistentVolumeClaims.
+	//  - in: query
+	//    name: pvcSize
+	//    type: string
+	//    description: Requested size of the generated PersistentVolumeClaims, as a Kubernetes quantity. Defaults to 1Gi.
 	// produces:
 	// - text/vnd.yaml
 	// - application/json
+	// - application/x-tar
 	// responses:
 	//   200:
 	//     description: Kubernetes YAML file describing pod
 	//     schema:
 	//      type: string
 	//      format: binary
+	//   400:
+	//     $ref: "#/responses/badParamError"
 	//   500:
 	//     $ref: "#/responses/internalError"
 	r.HandleFunc(VersionedPath("/libpod/generate/kube"), s.APIHandler(libpod.GenerateKube)).Methods(http.MethodGet)
@@ -261,6 +622,16 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	//    name: file
 	//    type: string
 	//    description: Path to the Kubernetes yaml file to deploy.
+	//  - in: query
+	//    name: serverSideApply
+	//    type: boolean
+	//    default: false
+	//    description: Use Kubernetes server-side apply instead of creating the objects.
+	//  - in: query
+	//    name: fieldManager
+	//    type: string
+	//    default: podman
+	//    description: Name of the field manager used for server-side apply.
 	//  - in: body
 	//    name: request
 	//    description: Kubernetes YAML file.
@@ -270,10 +641,11 @@ func (s *APIServer) registerKubeHandlers(r *mux.Router) error {
 	// - application/json
 	// responses:
 	//   200:
-	//     description: Kubernetes YAML file successfully deployed to cluster
-	//     schema:
-	//      type: string
-	//      format: binary
+	//     $ref: "#/responses/kubeApplyResponseLibpod"
+	//   400:
+	//     $ref: "#/responses/badParamError"
+	//   409:
+	//     $ref: "#/responses/conflictError"
 	//   500:
 	//     $ref: "#/responses/internalError"
 	r.HandleFunc(VersionedPath("/libpod/kube/apply"), s.APIHandler(libpod.KubeApply)).Methods(http.MethodPost)
@@ -20,6 +20,7 @@ import (
 	"github.com/containers/podman/v5/libpod"
 	"github.com/containers/podman/v5/libpod/shutdown"
 	"github.com/containers/podman/v5/pkg/api/handlers"
+	libpodHandlers "github.com/containers/podman/v5/pkg/api/handlers/libpod"
 	"github.com/containers/podman/v5/pkg/api/server/idle"
 	"github.com/containers/podman/v5/pkg/api/types"
 	"github.com/containers/podman/v5/pkg/domain/entities"
@@ -110,6 +111,10 @@ func newServer(runtime *libpod.Runtime, listener net.Listener, opts entities.Ser
 		}
 	}
 
+	if err := libpodHandlers.SetupKubePlayUploads(runtime); err != nil {
+		return nil, err
+	}
+
 	// Capture panics and print stack traces for diagnostics,
 	// additionally process X-Reference-Id Header to support event correlation
 	router.Use(panicHandler(), referenceIDHandler())
@@ -1,8 +1,12 @@
 package generate
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -64,9 +68,39 @@ func Kube(ctx context.Context, nameOrIDs []string, options *KubeOptions) (*types
 	}
 
 	if response.StatusCode == http.StatusOK {
+		if options.GetSplit() && response.Header.Get("Content-Type") == "application/x-tar" {
+			defer response.Body.Close()
+			return readSplitKube(response.Body)
+		}
 		return &types.GenerateKubeReport{Reader: response.Body}, nil
 	}
 
 	// Unpack the error.
 	return nil, response.Process(nil)
 }
+
+// readSplitKube reads the tar archive of a split Kube generation into the
+// files of the report, keeping the archive in its reader.
+func readSplitKube(r io.Reader) (*types.GenerateKubeReport, error) {
+	archive, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading generated files: %w", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading generated file %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = content
+	}
+	return &types.GenerateKubeReport{Reader: bytes.NewReader(archive), Files: files}, nil
+}
@@ -5,6 +5,7 @@ package generate
 //go:generate go run ../generator/generator.go KubeOptions
 type KubeOptions struct {
 	// PodmanOnly - add podman-only reserved annotations to generated YAML file (Cannot be used by Kubernetes)
+	// Healthchecks are then not converted to liveness probes.
 	PodmanOnly *bool
 	// Service - generate YAML for a Kubernetes _service_ object.
 	Service *bool
@@ -14,6 +15,19 @@ type KubeOptions struct {
 	Replicas *int32
 	// NoTrunc - don't truncate annotations to the Kubernetes maximum length of 63 characters
 	NoTrunc *bool
+	// NetworkPolicy - generate a NetworkPolicy for each network the pods are attached to
+	NetworkPolicy *bool
+	// Format - output format, "yaml" (default) or "jsonl" for one JSON object per line
+	Format *string
+	// Split - return a YAML file per kube kind, see GenerateKubeReport.Files
+	Split *bool
+	// PVC - generate a PersistentVolumeClaim for each named volume of the
+	// pods and containers
+	PVC *bool
+	// PVCStorageClass - storage class of the generated PersistentVolumeClaims
+	PVCStorageClass *string
+	// PVCSize - storage requested by the generated PersistentVolumeClaims
+	PVCSize *string
 }
 
 // SystemdOptions are optional options for generating systemd files
@@ -91,3 +91,93 @@ func (o *KubeOptions) GetNoTrunc() bool {
 	}
 	return *o.NoTrunc
 }
+
+// WithNetworkPolicy set field NetworkPolicy to given value
+func (o *KubeOptions) WithNetworkPolicy(value bool) *KubeOptions {
+	o.NetworkPolicy = &value
+	return o
+}
+
+// GetNetworkPolicy returns value of field NetworkPolicy
+func (o *KubeOptions) GetNetworkPolicy() bool {
+	if o.NetworkPolicy == nil {
+		var z bool
+		return z
+	}
+	return *o.NetworkPolicy
+}
+
+// WithFormat set field Format to given value
+func (o *KubeOptions) WithFormat(value string) *KubeOptions {
+	o.Format = &value
+	return o
+}
+
+// GetFormat returns value of field Format
+func (o *KubeOptions) GetFormat() string {
+	if o.Format == nil {
+		var z string
+		return z
+	}
+	return *o.Format
+}
+
+// WithSplit set field Split to given value
+func (o *KubeOptions) WithSplit(value bool) *KubeOptions {
+	o.Split = &value
+	return o
+}
+
+// GetSplit returns value of field Split
+func (o *KubeOptions) GetSplit() bool {
+	if o.Split == nil {
+		var z bool
+		return z
+	}
+	return *o.Split
+}
+
+// WithPVC set field PVC to given value
+func (o *KubeOptions) WithPVC(value bool) *KubeOptions {
+	o.PVC = &value
+	return o
+}
+
+// GetPVC returns value of field PVC
+func (o *KubeOptions) GetPVC() bool {
+	if o.PVC == nil {
+		var z bool
+		return z
+	}
+	return *o.PVC
+}
+
+// WithPVCStorageClass set field PVCStorageClass to given value
+func (o *KubeOptions) WithPVCStorageClass(value string) *KubeOptions {
+	o.PVCStorageClass = &value
+	return o
+}
+
+// GetPVCStorageClass returns value of field PVCStorageClass
+func (o *KubeOptions) GetPVCStorageClass() string {
+	if o.PVCStorageClass == nil {
+		var z string
+		return z
+	}
+	return *o.PVCStorageClass
+}
+
+// WithPVCSize set field PVCSize to given value
+func (o *KubeOptions) WithPVCSize(value string) *KubeOptions {
+	o.PVCSize = &value
+	return o
+}
+
+// GetPVCSize returns value of field PVCSize
+func (o *KubeOptions) GetPVCSize() string {
+	if o.PVCSize == nil {
+		var z string
+		return z
+	}
+	return *o.PVCSize
+}
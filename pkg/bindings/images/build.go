@@ -1,13 +1,11 @@
 package images
 
 import (
-	"archive/tar"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -23,27 +21,20 @@ import (
 	ldefine "github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/pkg/auth"
 	"github.com/containers/podman/v5/pkg/bindings"
+	bindingsUtil "github.com/containers/podman/v5/pkg/bindings/internal/util"
 	"github.com/containers/podman/v5/pkg/domain/entities/types"
 	"github.com/containers/podman/v5/pkg/specgen"
 	"github.com/containers/podman/v5/pkg/util"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/go-units"
-	"github.com/hashicorp/go-multierror"
 	jsoniter "github.com/json-iterator/go"
-	gzip "github.com/klauspost/pgzip"
 	"github.com/sirupsen/logrus"
 	imageTypes "go.podman.io/image/v5/types"
 	"go.podman.io/storage/pkg/archive"
 	"go.podman.io/storage/pkg/fileutils"
-	"go.podman.io/storage/pkg/ioutils"
 	"go.podman.io/storage/pkg/regexp"
 )
 
-type devino struct {
-	Dev uint64
-	Ino uint64
-}
-
 var iidRegex = regexp.Delayed(`^[0-9a-f]{12}`)
 
 type BuildResponse struct {
@@ -71,17 +62,25 @@ type RequestParts struct {
 
 // Modify the build contexts that uses a local windows path. The windows path is
 // converted into the corresping guest path in the default Windows machine
-// (e.g. C:\test ==> /mnt/c/test).
-func convertAdditionalBuildContexts(additionalBuildContexts map[string]*define.AdditionalBuildContext) {
-	for _, context := range additionalBuildContexts {
+// (e.g. C:\test ==> /mnt/c/test). UNC paths to the files of the machine
+// (e.g. \\wsl$\podman-machine-default\src ==> /src) are converted too, other
+// UNC paths and drive-relative paths (e.g. C:test) that could not be made
+// absolute cannot be reached from the machine and are rejected.
+func convertAdditionalBuildContexts(additionalBuildContexts map[string]*define.AdditionalBuildContext) error {
+	for name, context := range additionalBuildContexts {
 		if !context.IsImage && !context.IsURL {
 			path, err := specgen.ConvertWinMountPath(context.Value)
-			// It's not worth failing if the path can't be converted
-			if err == nil {
-				context.Value = path
+			if err != nil {
+				// It's not worth failing if any other path can't be converted
+				if errors.Is(err, specgen.ErrUntranslatableWinPath) {
+					return fmt.Errorf("build context %s: %w", name, err)
+				}
+				continue
 			}
+			context.Value = path
 		}
 	}
+	return nil
 }
 
 // convertVolumeSrcPath converts windows paths in the HOST-DIR part of a volume
@@ -655,7 +654,13 @@ func prepareSecrets(secrets []string, contextDir string, tempManager *remote_bui
 // additional build contexts, supporting URLs, images, and local directories.
 // WARNING: Caller must close request body.
 func prepareRemoteRequestBody(ctx context.Context, requestParts *RequestParts, buildFilePaths *BuildFilePaths, options types.BuildOptions) (*RequestParts, error) {
-	tarfile, err := nTar(append(buildFilePaths.excludes, buildFilePaths.dontexcludes...), buildFilePaths.tarContent...)
+	excludes := append(buildFilePaths.excludes, buildFilePaths.dontexcludes...)
+	if logrus.IsLevelEnabled(logrus.DebugLevel) {
+		if included, excluded, err := bindingsUtil.DryRunTar(excludes, buildFilePaths.tarContent...); err == nil {
+			logrus.Debugf("Build context packs %v, excludes %v", included, excluded)
+		}
+	}
+	tarfile, err := bindingsUtil.CreateTarContext(ctx, excludes, buildFilePaths.tarContent...)
 	if err != nil {
 		logrus.Errorf("Cannot tar container entries %v error: %v", buildFilePaths.tarContent, err)
 		return nil, err
@@ -714,7 +719,9 @@ func prepareRemoteRequestBody(ctx context.Context, requestParts *RequestParts, b
 	}
 
 	if !isSupported {
-		convertAdditionalBuildContexts(options.AdditionalBuildContexts)
+		if err := convertAdditionalBuildContexts(options.AdditionalBuildContexts); err != nil {
+			return nil, err
+		}
 		additionalBuildContextMap, err := jsoniter.Marshal(options.AdditionalBuildContexts)
 		if err != nil {
 			return nil, err
@@ -813,7 +820,7 @@ func prepareRemoteRequestBody(ctx context.Context, requestParts *RequestParts, b
 				}
 				file.Close()
 			} else {
-				tarContent, err := nTar(nil, context.Value)
+				tarContent, err := bindingsUtil.CreateTarContext(ctx, nil, context.Value)
 				if err != nil {
 					pw.CloseWithError(fmt.Errorf("creating tar content %q: %w", name, err))
 					return
@@ -1068,165 +1075,3 @@ func build(ctx context.Context, containerFiles []string, options types.BuildOpti
 
 	return processBuildResponse(response, stdout, saveFormat)
 }
-
-func nTar(excludes []string, sources ...string) (io.ReadCloser, error) {
-	pm, err := fileutils.NewPatternMatcher(excludes)
-	if err != nil {
-		return nil, fmt.Errorf("processing excludes list %v: %w", excludes, err)
-	}
-
-	if len(sources) == 0 {
-		return nil, errors.New("no source(s) provided for build")
-	}
-
-	pr, pw := io.Pipe()
-	gw := gzip.NewWriter(pw)
-	tw := tar.NewWriter(gw)
-
-	var merr *multierror.Error
-	go func() {
-		defer pw.Close()
-		defer gw.Close()
-		defer tw.Close()
-		seen := make(map[devino]string)
-		for i, src := range sources {
-			source, err := filepath.Abs(src)
-			if err != nil {
-				logrus.Errorf("Cannot stat one of source context: %v", err)
-				merr = multierror.Append(merr, err)
-				return
-			}
-			err = filepath.WalkDir(source, func(path string, dentry fs.DirEntry, err error) error {
-				if err != nil {
-					return err
-				}
-
-				separator := string(filepath.Separator)
-				// check if what we are given is an empty dir, if so then continue w/ it. Else return.
-				// if we are given a file or a symlink, we do not want to exclude it.
-				if source == path {
-					separator = ""
-					if dentry.IsDir() {
-						var p *os.File
-						p, err = os.Open(path)
-						if err != nil {
-							return err
-						}
-						defer p.Close()
-						_, err = p.Readdir(1)
-						if err == nil {
-							return nil // non empty root dir, need to return
-						}
-						if err != io.EOF {
-							logrus.Errorf("While reading directory %v: %v", path, err)
-						}
-					}
-				}
-				var name string
-				if i == 0 {
-					name = filepath.ToSlash(strings.TrimPrefix(path, source+separator))
-				} else {
-					if !dentry.Type().IsRegular() {
-						return fmt.Errorf("path %s must be a regular file", path)
-					}
-					name = filepath.ToSlash(path)
-				}
-				// If name is absolute path, then it has to be containerfile outside of build context.
-				// If not, we should check it for being excluded via pattern matcher.
-				if !filepath.IsAbs(name) {
-					excluded, err := pm.Matches(name) //nolint:staticcheck
-					if err != nil {
-						return fmt.Errorf("checking if %q is excluded: %w", name, err)
-					}
-					if excluded {
-						// Note: filepath.SkipDir is not possible to use given .dockerignore semantics.
-						// An exception to exclusions may include an excluded directory, therefore we
-						// are required to visit all files. :(
-						return nil
-					}
-				}
-				switch {
-				case dentry.Type().IsRegular(): // add file item
-					info, err := dentry.Info()
-					if err != nil {
-						return err
-					}
-					di, isHardLink := checkHardLink(info)
-
-					hdr, err := tar.FileInfoHeader(info, "")
-					if err != nil {
-						return err
-					}
-					hdr.Uid, hdr.Gid = 0, 0
-					orig, ok := seen[di]
-					if ok {
-						hdr.Typeflag = tar.TypeLink
-						hdr.Linkname = orig
-						hdr.Size = 0
-						hdr.Name = name
-						return tw.WriteHeader(hdr)
-					}
-					f, err := os.Open(path)
-					if err != nil {
-						return err
-					}
-
-					hdr.Name = name
-					if err := tw.WriteHeader(hdr); err != nil {
-						f.Close()
-						return err
-					}
-
-					_, err = io.Copy(tw, f)
-					f.Close()
-					if err == nil && isHardLink {
-						seen[di] = name
-					}
-					return err
-				case dentry.IsDir(): // add folders
-					info, err := dentry.Info()
-					if err != nil {
-						return err
-					}
-					hdr, lerr := tar.FileInfoHeader(info, name)
-					if lerr != nil {
-						return lerr
-					}
-					hdr.Name = name
-					hdr.Uid, hdr.Gid = 0, 0
-					if lerr := tw.WriteHeader(hdr); lerr != nil {
-						return lerr
-					}
-				case dentry.Type()&os.ModeSymlink != 0: // add symlinks as it, not content
-					link, err := os.Readlink(path)
-					if err != nil {
-						return err
-					}
-					info, err := dentry.Info()
-					if err != nil {
-						return err
-					}
-					hdr, lerr := tar.FileInfoHeader(info, link)
-					if lerr != nil {
-						return lerr
-					}
-					hdr.Name = name
-					hdr.Uid, hdr.Gid = 0, 0
-					if lerr := tw.WriteHeader(hdr); lerr != nil {
-						return lerr
-					}
-				} // skip other than file,folder and symlinks
-				return nil
-			})
-			merr = multierror.Append(merr, err)
-		}
-	}()
-	rc := ioutils.NewReadCloserWrapper(pr, func() error {
-		if merr != nil {
-			merr = multierror.Append(merr, pr.Close())
-			return merr.ErrorOrNil()
-		}
-		return pr.Close()
-	})
-	return rc, nil
-}
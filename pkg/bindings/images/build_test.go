@@ -1,10 +1,15 @@
 package images
 
 import (
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/containers/buildah/define"
+	"github.com/containers/podman/v5/pkg/specgen"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildMatchIID(t *testing.T) {
@@ -45,7 +50,8 @@ func TestConvertAdditionalBuildContexts(t *testing.T) {
 		},
 	}
 
-	convertAdditionalBuildContexts(additionalBuildContexts)
+	err := convertAdditionalBuildContexts(additionalBuildContexts)
+	assert.NoError(t, err)
 
 	expectedGuestValues := map[string]string{
 		"context1": "/mnt/c/test",
@@ -58,3 +64,81 @@ func TestConvertAdditionalBuildContexts(t *testing.T) {
 		assert.Equal(t, expectedGuestValues[key], value.Value)
 	}
 }
+
+func TestConvertAdditionalBuildContextsWindowsPaths(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("windows paths are only converted on Windows clients")
+	}
+	additionalBuildContexts := map[string]*define.AdditionalBuildContext{
+		"context1": {
+			Value: "C:/test",
+		},
+		"context2": {
+			Value: "c:\\TEST\\",
+		},
+		"context3": {
+			Value: "C:\\a\\\\b",
+		},
+	}
+
+	err := convertAdditionalBuildContexts(additionalBuildContexts)
+	require.NoError(t, err)
+
+	expectedGuestValues := map[string]string{
+		"context1": "/mnt/c/test",
+		"context2": "/mnt/c/TEST",
+		"context3": "/mnt/c/a/b",
+	}
+
+	for key, value := range additionalBuildContexts {
+		assert.Equal(t, expectedGuestValues[key], value.Value)
+	}
+}
+
+func TestConvertAdditionalBuildContextsNetworkShare(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("windows paths are only converted on Windows clients")
+	}
+	additionalBuildContexts := map[string]*define.AdditionalBuildContext{
+		"context1": {
+			Value: "\\\\host\\share\\test",
+		},
+	}
+
+	err := convertAdditionalBuildContexts(additionalBuildContexts)
+	assert.ErrorIs(t, err, specgen.ErrUntranslatableWinPath)
+	assert.ErrorContains(t, err, "build context context1")
+}
+
+func TestConvertAdditionalBuildContextsDriveRelative(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("windows paths are only converted on Windows clients")
+	}
+	// Windows clients resolve the path against the current directory of the
+	// drive before converting it.
+	abs, err := filepath.Abs("C:test")
+	require.NoError(t, err)
+	expected, err := specgen.ConvertWinMountPath(abs)
+	require.NoError(t, err)
+
+	additionalBuildContexts := map[string]*define.AdditionalBuildContext{
+		"context1": {
+			Value: "C:test",
+		},
+		"context2": {
+			IsURL: true,
+			Value: "C:test",
+		},
+		"context3": {
+			IsImage: true,
+			Value:   "C:test",
+		},
+	}
+
+	err = convertAdditionalBuildContexts(additionalBuildContexts)
+	require.NoError(t, err)
+	assert.Equal(t, expected, additionalBuildContexts["context1"].Value)
+	assert.True(t, strings.HasPrefix(expected, "/mnt/c/"), expected)
+	assert.Equal(t, "C:test", additionalBuildContexts["context2"].Value)
+	assert.Equal(t, "C:test", additionalBuildContexts["context3"].Value)
+}
@@ -1,13 +1,13 @@
 //go:build !windows
 
-package images
+package util
 
 import (
 	"os"
 	"syscall"
 )
 
-func checkHardLink(fi os.FileInfo) (devino, bool) {
+func checkHardLink(_ string, fi os.FileInfo) (devino, bool) {
 	st := fi.Sys().(*syscall.Stat_t)
 	return devino{
 		Dev: uint64(st.Dev), //nolint:unconvert,nolintlint
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+)
+
+// checkHardLink identifies the file by its volume serial number and file
+// index. If the file cannot be queried it is reported as not linked so that
+// its content is simply copied.
+func checkHardLink(path string, _ os.FileInfo) (devino, bool) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return devino{}, false
+	}
+	h, err := windows.CreateFile(p, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		logrus.Debugf("Cannot open %s to check for hard links: %v", path, err)
+		return devino{}, false
+	}
+	defer windows.CloseHandle(h) //nolint:errcheck
+
+	var info windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(h, &info); err != nil {
+		logrus.Debugf("Cannot get file information of %s: %v", path, err)
+		return devino{}, false
+	}
+	return devino{
+		Dev: uint64(info.VolumeSerialNumber),
+		Ino: uint64(info.FileIndexHigh)<<32 | uint64(info.FileIndexLow),
+	}, info.NumberOfLinks > 1
+}
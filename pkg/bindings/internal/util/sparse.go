@@ -0,0 +1,178 @@
+package util
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const blockSize = 512
+
+// errSparseFileChanged is returned when a sparse file changes while it is
+// stored, its entry would not match the data written.
+var errSparseFileChanged = errors.New("sparse file changed while being archived")
+
+// sparseRegion is a region of a sparse file holding data.
+type sparseRegion struct {
+	offset int64
+	length int64
+}
+
+// paxRecord formats a PAX extended header record, whose length prefix
+// accounts for itself.
+func paxRecord(key, value string) string {
+	const padding = 3 // Extra padding for ' ', '=', and '\n'
+	size := len(key) + len(value) + padding
+	size += len(strconv.Itoa(size))
+	record := strconv.Itoa(size) + " " + key + "=" + value + "\n"
+	// The length of the length may have grown with its own digits.
+	if len(record) != size {
+		size = len(record)
+		record = strconv.Itoa(size) + " " + key + "=" + value + "\n"
+	}
+	return record
+}
+
+// formatOctal writes v in the NUL terminated octal field b, reporting
+// whether it fits.
+func formatOctal(b []byte, v int64) bool {
+	s := strconv.FormatInt(v, 8)
+	if v < 0 || len(s) > len(b)-1 {
+		return false
+	}
+	for i := range b {
+		b[i] = 0
+	}
+	copy(b, strings.Repeat("0", len(b)-1-len(s))+s)
+	return true
+}
+
+// ustarBlock encodes a USTAR header block. Names are truncated to the
+// fields: the real names of the sparse entries are carried by PAX records.
+func ustarBlock(name string, typeflag byte, size int64, hdr *tar.Header) ([]byte, bool) {
+	blk := make([]byte, blockSize)
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+	copy(blk[0:100], name)
+	ok := formatOctal(blk[100:108], hdr.Mode&0o7777) &&
+		formatOctal(blk[108:116], int64(hdr.Uid)) &&
+		formatOctal(blk[116:124], int64(hdr.Gid)) &&
+		formatOctal(blk[124:136], size) &&
+		formatOctal(blk[136:148], hdr.ModTime.Unix())
+	if !ok {
+		return nil, false
+	}
+	blk[156] = typeflag
+	copy(blk[257:265], "ustar\x0000")
+	copy(blk[265:297], hdr.Uname)
+	copy(blk[297:329], hdr.Gname)
+
+	copy(blk[148:156], "        ")
+	var sum int64
+	for _, c := range blk {
+		sum += int64(c)
+	}
+	copy(blk[148:156], fmt.Sprintf("%06o\x00 ", sum))
+	return blk, true
+}
+
+// padBlock returns the zeros completing n bytes to a multiple of blockSize.
+func padBlock(n int64) []byte {
+	return make([]byte, -n&(blockSize-1))
+}
+
+// writeSparse writes hdr and the data regions of f to w, the writer
+// underlying tw, as a PAX 1.0 GNU sparse entry so the holes are not stored.
+// It returns false without writing anything when hdr cannot be encoded that
+// way, leaving the caller to store the file densely.
+func writeSparse(tw *tar.Writer, w io.Writer, hdr *tar.Header, f *os.File, regions []sparseRegion) (bool, error) {
+	sparseMap := strconv.Itoa(len(regions)) + "\n"
+	var dataSize int64
+	for _, r := range regions {
+		sparseMap += strconv.FormatInt(r.offset, 10) + "\n" + strconv.FormatInt(r.length, 10) + "\n"
+		dataSize += r.length
+	}
+	mapBytes := append([]byte(sparseMap), padBlock(int64(len(sparseMap)))...)
+
+	records := paxRecord("GNU.sparse.major", "1") +
+		paxRecord("GNU.sparse.minor", "0") +
+		paxRecord("GNU.sparse.name", hdr.Name) +
+		paxRecord("GNU.sparse.realsize", strconv.FormatInt(hdr.Size, 10))
+	if len(hdr.Uname) > 32 {
+		records += paxRecord("uname", hdr.Uname)
+	}
+	if len(hdr.Gname) > 32 {
+		records += paxRecord("gname", hdr.Gname)
+	}
+	keys := make([]string, 0, len(hdr.PAXRecords))
+	for k := range hdr.PAXRecords {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		records += paxRecord(k, hdr.PAXRecords[k])
+	}
+
+	dir, file := path.Split(hdr.Name)
+	paxBlock, ok := ustarBlock(path.Join(dir, "PaxHeaders.0", file), tar.TypeXHeader, int64(len(records)), hdr)
+	if !ok {
+		return false, nil
+	}
+	entryBlock, ok := ustarBlock(path.Join(dir, "GNUSparseFile.0", file), tar.TypeReg, int64(len(mapBytes))+dataSize, hdr)
+	if !ok {
+		return false, nil
+	}
+
+	// The entry is written to w directly as tar.Writer cannot encode sparse
+	// entries. This is safe between entries: Flush pads the previous entry to
+	// a block boundary and tw keeps no state about this one, so its next
+	// WriteHeader or Close writes after the blocks written here.
+	if err := tw.Flush(); err != nil {
+		return true, err
+	}
+	for _, b := range [][]byte{paxBlock, []byte(records), padBlock(int64(len(records))), entryBlock, mapBytes} {
+		if _, err := w.Write(b); err != nil {
+			return true, err
+		}
+	}
+	for _, r := range regions {
+		if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+			return true, err
+		}
+		if _, err := io.CopyN(w, f, r.length); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errSparseFileChanged
+			}
+			return true, fmt.Errorf("copying data of sparse file %s: %w", f.Name(), err)
+		}
+	}
+	// The headers were sized from regions computed before the copy.
+	if err := checkSparseRegions(f, hdr.Size, regions); err != nil {
+		return true, err
+	}
+	_, err := w.Write(padBlock(dataSize))
+	return true, err
+}
+
+// checkSparseRegions fails with errSparseFileChanged unless f still has size
+// bytes and the data regions it was stored with.
+func checkSparseRegions(f *os.File, size int64, regions []sparseRegion) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != size {
+		return fmt.Errorf("%s: %w: size changed from %d to %d", f.Name(), errSparseFileChanged, size, info.Size())
+	}
+	if current, ok := dataRegions(f, size); !ok || !slices.Equal(current, regions) {
+		return fmt.Errorf("%s: %w: data regions changed", f.Name(), errSparseFileChanged)
+	}
+	return nil
+}
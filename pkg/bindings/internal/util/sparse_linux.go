@@ -0,0 +1,52 @@
+package util
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dataRegions returns the data regions of the size first bytes of f using
+// SEEK_DATA and SEEK_HOLE. It returns false when f has no holes or the
+// filesystem cannot report them.
+func dataRegions(f *os.File, size int64) ([]sparseRegion, bool) {
+	var (
+		regions  []sparseRegion
+		dataSize int64
+	)
+	for off := int64(0); off < size; {
+		data, err := f.Seek(off, unix.SEEK_DATA)
+		if err != nil {
+			// ENXIO: only a hole is left up to the end of the file.
+			if errors.Is(err, unix.ENXIO) {
+				break
+			}
+			return nil, false
+		}
+		if data >= size {
+			break
+		}
+		hole, err := f.Seek(data, unix.SEEK_HOLE)
+		if err != nil {
+			return nil, false
+		}
+		hole = min(hole, size)
+		regions = append(regions, sparseRegion{offset: data, length: hole - data})
+		dataSize += hole - data
+		off = hole
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, false
+	}
+	if dataSize == size {
+		return nil, false
+	}
+	// GNU tar only extends a file ending with a hole up to its size when an
+	// empty region marks the end.
+	if n := len(regions); n == 0 || regions[n-1].offset+regions[n-1].length < size {
+		regions = append(regions, sparseRegion{offset: size})
+	}
+	return regions, true
+}
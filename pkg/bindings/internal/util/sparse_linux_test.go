@@ -0,0 +1,151 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.podman.io/storage/pkg/archive"
+)
+
+// createSparseFile creates a file of size bytes holding data at each of the
+// offsets, skipping the test when the filesystem does not report holes.
+func createSparseFile(t *testing.T, path string, size int64, data []byte, offsets ...int64) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	for _, offset := range offsets {
+		_, err = f.WriteAt(data, offset)
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Truncate(size))
+	if _, ok := dataRegions(f, size); !ok {
+		t.Skip("the filesystem of the temporary directory does not report holes")
+	}
+}
+
+// untarGzip returns the uncompressed tar of the gzip compressed rc.
+func untarGzip(t *testing.T, rc io.ReadCloser) *bytes.Buffer {
+	defer rc.Close()
+	gr, err := gzip.NewReader(rc)
+	require.NoError(t, err)
+	var archive bytes.Buffer
+	_, err = io.Copy(&archive, gr)
+	require.NoError(t, err)
+	return &archive
+}
+
+func TestCreateTarSparseFile(t *testing.T) {
+	const size = 64 << 20
+	contextDir := t.TempDir()
+	data := bytes.Repeat([]byte("data"), 1024)
+	createSparseFile(t, filepath.Join(contextDir, "disk.img"), size, data, size/2)
+
+	rc, err := CreateTarWithOptions(TarOptions{Sparse: true}, nil, contextDir)
+	require.NoError(t, err)
+	archive := untarGzip(t, rc)
+	assert.Less(t, archive.Len(), size/100)
+
+	tr := tar.NewReader(archive)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "disk.img", hdr.Name)
+	assert.Equal(t, int64(size), hdr.Size)
+	content, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	require.Len(t, content, size)
+	assert.Equal(t, data, content[size/2:size/2+len(data)])
+	assert.Equal(t, make([]byte, size/2), content[:size/2])
+	_, err = tr.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCreateTarSparseFileDense(t *testing.T) {
+	const size = 8 << 20
+	contextDir := t.TempDir()
+	createSparseFile(t, filepath.Join(contextDir, "disk.img"), size, []byte("data"), size/2)
+
+	// sparse entries are only written when asked for
+	rc, err := CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	archive := untarGzip(t, rc)
+	assert.Greater(t, archive.Len(), size)
+}
+
+func TestCreateTarSparseFileRoundTrip(t *testing.T) {
+	const size = 16 << 20
+	contextDir := t.TempDir()
+	data := bytes.Repeat([]byte("data"), 4096)
+	offsets := []int64{0, 4 << 20, size - int64(len(data))}
+	createSparseFile(t, filepath.Join(contextDir, "disk.img"), size, data, offsets...)
+	expected, err := os.ReadFile(filepath.Join(contextDir, "disk.img"))
+	require.NoError(t, err)
+
+	rc, err := CreateTarWithOptions(TarOptions{Sparse: true, Uncompressed: true}, nil, contextDir)
+	require.NoError(t, err)
+	defer rc.Close()
+	var stored countingWriter
+	dest := t.TempDir()
+	require.NoError(t, archive.Untar(io.TeeReader(rc, &stored), dest, &archive.TarOptions{NoLchown: true}))
+	// the holes are not stored
+	assert.Less(t, int64(stored), int64(size/100))
+
+	content, err := os.ReadFile(filepath.Join(dest, "disk.img"))
+	require.NoError(t, err)
+	require.Len(t, content, size)
+	assert.True(t, bytes.Equal(expected, content), "the extracted file differs from the source")
+	// the holes read back as zeros, the data regions as written
+	end := int64(0)
+	for _, offset := range offsets {
+		assert.Equal(t, make([]byte, offset-end), content[end:offset], "hole before %d", offset)
+		assert.Equal(t, data, content[offset:offset+int64(len(data))], "data at %d", offset)
+		end = offset + int64(len(data))
+	}
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter int64
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return len(p), nil
+}
+
+func TestWriteSparseFileChanged(t *testing.T) {
+	const size = 8 << 20
+	path := filepath.Join(t.TempDir(), "disk.img")
+
+	for name, change := range map[string]func(f *os.File) error{
+		"shrunk": func(f *os.File) error { return f.Truncate(size / 4) },
+		"grown":  func(f *os.File) error { return f.Truncate(size * 2) },
+		"filled": func(f *os.File) error {
+			_, err := f.WriteAt([]byte("data"), size/4)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			createSparseFile(t, path, size, []byte("data"), size/2)
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			require.NoError(t, err)
+			defer f.Close()
+			info, err := f.Stat()
+			require.NoError(t, err)
+			hdr, err := tar.FileInfoHeader(info, "")
+			require.NoError(t, err)
+			regions, ok := dataRegions(f, hdr.Size)
+			require.True(t, ok)
+
+			require.NoError(t, change(f))
+			var buf bytes.Buffer
+			sparse, err := writeSparse(tar.NewWriter(&buf), &buf, hdr, f, regions)
+			assert.True(t, sparse)
+			assert.ErrorIs(t, err, errSparseFileChanged)
+		})
+	}
+}
@@ -0,0 +1,612 @@
+package util
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containers/podman/v5/pkg/util"
+	"github.com/hashicorp/go-multierror"
+	gzip "github.com/klauspost/pgzip"
+	"github.com/sirupsen/logrus"
+	"go.podman.io/storage/pkg/fileutils"
+	"go.podman.io/storage/pkg/idtools"
+	"go.podman.io/storage/pkg/ioutils"
+	"go.podman.io/storage/pkg/system"
+)
+
+type devino struct {
+	Dev uint64
+	Ino uint64
+}
+
+// TarOptions controls how CreateTarWithOptions packs its sources.
+type TarOptions struct {
+	// PreserveOwnership keeps the uid/gid of the source files instead of
+	// flattening every entry to root.
+	PreserveOwnership bool
+	// ReadIgnoreFile merges the patterns of the .containerignore (or
+	// .dockerignore) file found at the root of the first source into the
+	// excludes. The ignore file itself is excluded unless a pattern
+	// re-includes it.
+	ReadIgnoreFile bool
+	// Deterministic sets every timestamp to the Unix epoch and drops the
+	// user and group names, so identical contexts produce identical tars.
+	// Entries are always written in lexical order.
+	Deterministic bool
+	// Progress, when set, is called after each regular file is written with
+	// its name in the archive and the cumulative size of the file contents
+	// written so far.
+	Progress func(path string, bytes int64)
+	// FollowSymlinks stores the content of the targets of symlinks under
+	// the names of the links instead of the links themselves. Links creating
+	// a loop are skipped.
+	FollowSymlinks bool
+	// PreserveXattrs stores the security.*, user.* and POSIX ACL extended
+	// attributes of the entries.
+	PreserveXattrs bool
+	// CompressionLevel is the gzip compression level of the stream, from
+	// gzip.HuffmanOnly to gzip.BestCompression. Zero and out of range values
+	// use gzip.DefaultCompression.
+	CompressionLevel int
+	// Uncompressed writes a plain tar stream instead of a gzip compressed
+	// one, CompressionLevel is then ignored.
+	Uncompressed bool
+	// IDMap, when set, maps the uid/gid of the source files from the host to
+	// the IDs stored in the entries, like rootless builds do, instead of
+	// flattening them to root. PreserveOwnership is then ignored and files
+	// owned by an unmapped ID fail the tar.
+	IDMap *idtools.IDMappings
+	// Sparse stores only the data regions of sparse files, as GNU sparse
+	// 1.0 PAX entries, on the filesystems reporting their holes. The
+	// extractor must support the format, as archive/tar, GNU tar and
+	// containers/storage do.
+	Sparse bool
+}
+
+// xattrPrefixes are the namespaces of the extended attributes stored with
+// PreserveXattrs.
+var xattrPrefixes = []string{"security.", "user.", "system.posix_acl_"}
+
+// adjustHeader applies the ownership and timestamp options to hdr.
+func (o TarOptions) adjustHeader(hdr *tar.Header) error {
+	switch {
+	case o.IDMap != nil:
+		uid, gid, err := o.IDMap.ToContainer(idtools.IDPair{UID: hdr.Uid, GID: hdr.Gid})
+		if err != nil {
+			return fmt.Errorf("mapping the ownership of %s: %w", hdr.Name, err)
+		}
+		hdr.Uid, hdr.Gid = uid, gid
+		// the names are the ones of the host IDs
+		hdr.Uname, hdr.Gname = "", ""
+	case !o.PreserveOwnership:
+		hdr.Uid, hdr.Gid = 0, 0
+	}
+	if o.Deterministic {
+		epoch := time.Unix(0, 0)
+		hdr.ModTime, hdr.AccessTime, hdr.ChangeTime = epoch, epoch, epoch
+		hdr.Uname, hdr.Gname = "", ""
+	}
+	return nil
+}
+
+// compressionLevel returns the gzip compression level to use, falling back to
+// the default one when CompressionLevel is unset or out of range.
+func (o TarOptions) compressionLevel() int {
+	switch {
+	case o.CompressionLevel == 0:
+		return gzip.DefaultCompression
+	case o.CompressionLevel < gzip.HuffmanOnly || o.CompressionLevel > gzip.BestCompression:
+		logrus.Warnf("Invalid gzip compression level %d, using the default level", o.CompressionLevel)
+		return gzip.DefaultCompression
+	default:
+		return o.CompressionLevel
+	}
+}
+
+// addXattrs records the extended attributes of path in hdr when
+// PreserveXattrs is set. Filesystems and platforms without extended
+// attributes are treated as having none.
+func (o TarOptions) addXattrs(hdr *tar.Header, path string) error {
+	if !o.PreserveXattrs {
+		return nil
+	}
+	names, err := system.Llistxattr(path)
+	if err != nil {
+		if errors.Is(err, system.ENOTSUP) || errors.Is(err, system.ErrNotSupportedPlatform) {
+			return nil
+		}
+		return fmt.Errorf("listing extended attributes of %s: %w", path, err)
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(xattrPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+			continue
+		}
+		value, err := system.Lgetxattr(path, name)
+		if err != nil {
+			return fmt.Errorf("reading extended attribute %s of %s: %w", name, path, err)
+		}
+		// The attribute was removed since it was listed.
+		if value == nil {
+			continue
+		}
+		if hdr.PAXRecords == nil {
+			hdr.PAXRecords = make(map[string]string)
+		}
+		hdr.PAXRecords["SCHILY.xattr."+name] = string(value)
+	}
+	return nil
+}
+
+// readIgnoreFile returns the exclude patterns of the ignore file at the root
+// of contextDir, preceded by a pattern excluding the ignore file itself so a
+// negated entry in the file can still bring it back.
+func readIgnoreFile(contextDir string) ([]string, error) {
+	patterns, ignoreFile, err := util.ParseDockerignore(nil, contextDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading ignore file in %s: %w", contextDir, err)
+	}
+	// ParseDockerignore reports /dev/null when no ignore file is present.
+	if ignoreFile == "/dev/null" || fileutils.Exists(ignoreFile) != nil {
+		return nil, nil
+	}
+	return append([]string{filepath.Base(ignoreFile)}, patterns...), nil
+}
+
+// absolutePatterns returns the patterns of excludes, negated or not, that
+// are absolute paths. The matcher only drops the leading slash of negated
+// patterns, so it is dropped from all of them and from the matched names.
+func absolutePatterns(excludes []string) []string {
+	var patterns []string
+	for _, e := range excludes {
+		e = strings.TrimSpace(e)
+		negated := strings.HasPrefix(e, "!")
+		p := strings.TrimPrefix(e, "!")
+		if !filepath.IsAbs(p) {
+			continue
+		}
+		p = strings.TrimPrefix(filepath.ToSlash(p), "/")
+		if negated {
+			p = "!" + p
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// symlinkLoop reports whether the symlink at path resolves to one of the
+// visited directories containing it, returning that directory.
+func symlinkLoop(path string, visitedDirs map[devino]string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return "", false
+	}
+	di, _ := checkHardLink(path, info)
+	if di == (devino{}) {
+		return "", false
+	}
+	dir, ok := visitedDirs[di]
+	if !ok {
+		return "", false
+	}
+	return dir, strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// followSymlink stores the target of the symlink at path under name: the
+// content of a regular file, or the tree of a directory walked with walkFn.
+// It returns false, leaving the link to be stored as-is, when the target
+// does not exist, and skips links resolving to a directory already being
+// followed.
+func followSymlink(path, name string, following map[devino]string, addFile func(path, name string, info fs.FileInfo) error, walkFn func(source, base string, extra bool) fs.WalkDirFunc) (bool, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		logrus.Warnf("Skipping symlink %s: %v", path, err)
+		return true, nil
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case info.Mode().IsRegular():
+		return true, addFile(target, name, info)
+	case info.IsDir():
+		di, _ := checkHardLink(target, info)
+		if di != (devino{}) {
+			if dir, ok := following[di]; ok {
+				logrus.Warnf("Skipping symlink %s: it points to %s and creates a loop", path, dir)
+				return true, nil
+			}
+			following[di] = target
+			defer delete(following, di)
+		}
+		return true, filepath.WalkDir(target, walkFn(target, name, false))
+	default:
+		return false, nil
+	}
+}
+
+// CreateTar returns a gzip compressed tar stream of the given sources with
+// every entry owned by root. The first source is the context directory, its
+// content is stored relative to it and filtered through excludes. Additional
+// sources must be regular files or directories and are stored, with the tree
+// of directories, under their absolute path.
+func CreateTar(excludes []string, sources ...string) (io.ReadCloser, error) {
+	return CreateTarWithOptions(TarOptions{}, excludes, sources...)
+}
+
+// CreateTarContext behaves like CreateTar and stops walking the sources with
+// ctx.Err() as soon as ctx is done.
+func CreateTarContext(ctx context.Context, excludes []string, sources ...string) (io.ReadCloser, error) {
+	return createTar(ctx, TarOptions{}, excludes, sources...)
+}
+
+// CreateTarWithProgress behaves like CreateTar and reports its progress
+// through progress, see TarOptions.Progress.
+func CreateTarWithProgress(progress func(path string, bytes int64), excludes []string, sources ...string) (io.ReadCloser, error) {
+	return CreateTarWithOptions(TarOptions{Progress: progress}, excludes, sources...)
+}
+
+// CreateTarWithOptions behaves like CreateTar with the behavior tuned by opts.
+func CreateTarWithOptions(opts TarOptions, excludes []string, sources ...string) (io.ReadCloser, error) {
+	return createTar(context.Background(), opts, excludes, sources...)
+}
+
+func createTar(ctx context.Context, opts TarOptions, excludes []string, sources ...string) (io.ReadCloser, error) {
+	walker, err := newTarWalker(ctx, opts, excludes, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	// gw is the stream under the tar writer, a plain tar is written straight
+	// to the pipe.
+	gw := ioutils.NopWriteCloser(pw)
+	if !opts.Uncompressed {
+		gw, err = gzip.NewWriterLevel(pw, opts.compressionLevel())
+		if err != nil {
+			return nil, err
+		}
+	}
+	tw := tar.NewWriter(gw)
+
+	var merr *multierror.Error
+	go func() {
+		// Readers draining the stream see the walk errors as a read error,
+		// not only when closing it.
+		defer func() { pw.CloseWithError(merr.ErrorOrNil()) }()
+		defer gw.Close()
+		defer tw.Close()
+		seen := make(map[devino]string)
+		var written int64
+		reportProgress := func(name string, n int64) {
+			written += n
+			if opts.Progress != nil {
+				opts.Progress(name, written)
+			}
+		}
+
+		addFile := func(path, name string, info fs.FileInfo) error {
+			di, isHardLink := checkHardLink(path, info)
+
+			// FileInfoHeader maps the setuid, setgid and sticky bits to
+			// the mode of the header, adjustHeader leaves it untouched.
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if err := opts.adjustHeader(hdr); err != nil {
+				return err
+			}
+			orig, ok := seen[di]
+			if ok {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Linkname = orig
+				hdr.Size = 0
+				if err := tw.WriteHeader(hdr); err != nil {
+					return err
+				}
+				reportProgress(name, 0)
+				return nil
+			}
+			if err := opts.addXattrs(hdr, path); err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+
+			var n int64
+			sparse := false
+			// Only the data regions of sparse files are stored.
+			if opts.Sparse {
+				if regions, ok := dataRegions(f, hdr.Size); ok {
+					sparse, err = writeSparse(tw, gw, hdr, f, regions)
+					for _, r := range regions {
+						n += r.length
+					}
+				}
+			}
+			if !sparse {
+				if err := tw.WriteHeader(hdr); err != nil {
+					f.Close()
+					return err
+				}
+				n, err = io.Copy(tw, f)
+			}
+			f.Close()
+			if err == nil {
+				reportProgress(name, n)
+			}
+			// Extra sources may point at a file already stored from the
+			// context directory, so remember every file when there are
+			// several sources, not only the ones with multiple links.
+			if err == nil && (isHardLink || len(sources) > 1) && di != (devino{}) {
+				seen[di] = name
+			}
+			return err
+		}
+
+		walker.add = func(path, name string, info fs.FileInfo, link string) error {
+			if info.Mode().IsRegular() {
+				return addFile(path, name, info)
+			}
+			// folders and symlinks, stored as is and not their content
+			hdr, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			if err := opts.adjustHeader(hdr); err != nil {
+				return err
+			}
+			if err := opts.addXattrs(hdr, path); err != nil {
+				return err
+			}
+			return tw.WriteHeader(hdr)
+		}
+		merr = walker.walk(sources)
+	}()
+	rc := ioutils.NewReadCloserWrapper(pr, func() error {
+		if merr != nil {
+			merr = multierror.Append(merr, pr.Close())
+			return merr.ErrorOrNil()
+		}
+		return pr.Close()
+	})
+	return rc, nil
+}
+
+// DryRunTar walks the sources like CreateTar and returns the names the tar
+// would hold and the names left out by excludes, without reading the files.
+func DryRunTar(excludes []string, sources ...string) (included []string, excluded []string, err error) {
+	return DryRunTarWithOptions(TarOptions{}, excludes, sources...)
+}
+
+// DryRunTarWithOptions behaves like DryRunTar with the walk tuned by opts,
+// see CreateTarWithOptions. Only the options selecting the entries matter.
+func DryRunTarWithOptions(opts TarOptions, excludes []string, sources ...string) (included []string, excluded []string, err error) {
+	walker, err := newTarWalker(context.Background(), opts, excludes, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+	walker.add = func(_, name string, _ fs.FileInfo, _ string) error {
+		included = append(included, name)
+		return nil
+	}
+	walker.skip = func(name string) {
+		excluded = append(excluded, name)
+	}
+	if err := walker.walk(sources).ErrorOrNil(); err != nil {
+		return nil, nil, err
+	}
+	return included, excluded, nil
+}
+
+// tarWalker walks the sources of a tar and classifies their entries, so
+// CreateTar and DryRunTar keep the same entries.
+type tarWalker struct {
+	ctx  context.Context
+	opts TarOptions
+	// pm matches the names relative to the context directory, absPm the
+	// absolute names of the sources out of it.
+	pm, absPm *fileutils.PatternMatcher
+	// visitedDirs holds the directories walked so far, to detect symlinks
+	// creating a loop.
+	visitedDirs map[devino]string
+	// following holds the directories whose symlinks are being followed,
+	// so a link pointing back into one of them is not followed again.
+	following map[devino]string
+	// add is called for every regular file, folder and symlink kept, with
+	// its path and its name in the tar. link is the target of symlinks.
+	add func(path, name string, info fs.FileInfo, link string) error
+	// skip, when set, is called with the name of every excluded entry.
+	skip func(name string)
+}
+
+// newTarWalker returns a walker of sources applying excludes, merged with the
+// ignore file patterns when opts.ReadIgnoreFile is set. The caller sets add.
+func newTarWalker(ctx context.Context, opts TarOptions, excludes []string, sources []string) (*tarWalker, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("no source(s) provided for build")
+	}
+
+	if opts.ReadIgnoreFile {
+		ignoreExcludes, err := readIgnoreFile(sources[0])
+		if err != nil {
+			return nil, err
+		}
+		excludes = append(ignoreExcludes, excludes...)
+	}
+
+	pm, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return nil, fmt.Errorf("processing excludes list %v: %w", excludes, err)
+	}
+	// Sources out of the context are stored under their absolute name and
+	// are only matched against the absolute patterns.
+	absPm, err := fileutils.NewPatternMatcher(absolutePatterns(excludes))
+	if err != nil {
+		return nil, fmt.Errorf("processing excludes list %v: %w", excludes, err)
+	}
+	return &tarWalker{
+		ctx:         ctx,
+		opts:        opts,
+		pm:          pm,
+		absPm:       absPm,
+		visitedDirs: make(map[devino]string),
+		following:   make(map[devino]string),
+	}, nil
+}
+
+// walk walks every source, the first one being the context directory, and
+// returns the errors met. It stops as soon as the context is done.
+func (w *tarWalker) walk(sources []string) *multierror.Error {
+	var merr *multierror.Error
+	for i, src := range sources {
+		source, err := filepath.Abs(src)
+		if err != nil {
+			logrus.Errorf("Cannot stat one of source context: %v", err)
+			return multierror.Append(merr, err)
+		}
+		err = filepath.WalkDir(source, w.walkFn(source, "", i > 0))
+		merr = multierror.Append(merr, err)
+		if w.ctx.Err() != nil {
+			return merr
+		}
+	}
+	return merr
+}
+
+// addFile adds the regular file at path, or followed to path, as name.
+func (w *tarWalker) addFile(path, name string, info fs.FileInfo) error {
+	return w.add(path, name, info, "")
+}
+
+// walkFn returns the function walking source. Entries of the context
+// directory are stored relative to it, under base when source is the target
+// of a followed symlink. Extra sources and the entries of extra directories
+// keep their absolute name.
+func (w *tarWalker) walkFn(source, base string, extra bool) fs.WalkDirFunc {
+	return func(path string, dentry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+
+		if dentry.IsDir() {
+			info, err := dentry.Info()
+			if err != nil {
+				return err
+			}
+			if di, _ := checkHardLink(path, info); di != (devino{}) {
+				w.visitedDirs[di] = path
+			}
+		}
+
+		separator := string(filepath.Separator)
+		// check if what we are given is an empty dir, if so then continue w/ it. Else return.
+		// if we are given a file or a symlink, we do not want to exclude it.
+		if source == path {
+			separator = ""
+			if dentry.IsDir() && base == "" && !extra {
+				var p *os.File
+				p, err = os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer p.Close()
+				_, err = p.Readdir(1)
+				if err == nil {
+					return nil // non empty root dir, need to return
+				}
+				if err != io.EOF {
+					logrus.Errorf("While reading directory %v: %v", path, err)
+				}
+			}
+		}
+		var name string
+		if !extra {
+			name = filepath.ToSlash(strings.TrimPrefix(path, source+separator))
+			if base != "" {
+				name = strings.TrimSuffix(base+"/"+name, "/")
+			}
+		} else {
+			if source == path && !dentry.Type().IsRegular() && !dentry.IsDir() {
+				return fmt.Errorf("path %s must be a regular file or a directory", path)
+			}
+			name = filepath.ToSlash(path)
+		}
+		// If name is absolute path, then it has to be containerfile outside of build context
+		// and only the absolute patterns apply to it.
+		matcher, matchName := w.pm, name
+		if filepath.IsAbs(name) {
+			matcher, matchName = w.absPm, strings.TrimPrefix(name, "/")
+		}
+		excluded, err := matcher.Matches(matchName) //nolint:staticcheck
+		if err != nil {
+			return fmt.Errorf("checking if %q is excluded: %w", name, err)
+		}
+		if excluded {
+			if w.skip != nil {
+				w.skip(name)
+			}
+			// Note: filepath.SkipDir is not possible to use given .dockerignore semantics.
+			// An exception to exclusions may include an excluded directory, therefore we
+			// are required to visit all files. :(
+			return nil
+		}
+		switch {
+		case dentry.Type().IsRegular(): // add file item
+			info, err := dentry.Info()
+			if err != nil {
+				return err
+			}
+			return w.addFile(path, name, info)
+		case dentry.IsDir(): // add folders
+			info, err := dentry.Info()
+			if err != nil {
+				return err
+			}
+			return w.add(path, name, info, "")
+		case dentry.Type()&os.ModeSymlink != 0: // add symlinks as it, not content
+			if dir, loop := symlinkLoop(path, w.visitedDirs); loop {
+				logrus.Warnf("Skipping symlink %s: it points to %s and creates a loop", path, dir)
+				return nil
+			}
+			if w.opts.FollowSymlinks {
+				followed, err := followSymlink(path, name, w.following, w.addFile, w.walkFn)
+				if followed || err != nil {
+					return err
+				}
+			}
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			info, err := dentry.Info()
+			if err != nil {
+				return err
+			}
+			return w.add(path, name, info, link)
+		default: // skip other than file,folder and symlinks
+			logrus.Warnf("Skipping %s: unsupported file type %s", path, dentry.Type())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,476 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.podman.io/storage/pkg/idtools"
+)
+
+// readTar drains the gzip compressed tar stream and returns its headers keyed by name.
+func readTar(t *testing.T, rc io.ReadCloser) map[string]*tar.Header {
+	t.Helper()
+	defer rc.Close()
+
+	gr, err := gzip.NewReader(rc)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	headers := make(map[string]*tar.Header)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		headers[hdr.Name] = hdr
+	}
+	return headers
+}
+
+func TestCreateTar(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(contextDir, "foo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "foo", "Containerfile"), []byte("FROM scratch\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "excluded.txt"), []byte("excluded"), 0o644))
+
+	rc, err := CreateTar([]string{"excluded.txt"}, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+
+	assert.Contains(t, headers, "foo")
+	assert.Contains(t, headers, "foo/Containerfile")
+	assert.NotContains(t, headers, "excluded.txt")
+	for name, hdr := range headers {
+		assert.Zero(t, hdr.Uid, name)
+		assert.Zero(t, hdr.Gid, name)
+	}
+}
+
+func TestCreateTarWithOptionsPreserveOwnership(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file ownership is not available on Windows")
+	}
+
+	contextDir := t.TempDir()
+	file := filepath.Join(contextDir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("content"), 0o644))
+
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid == 0 {
+		uid, gid = 1234, 1234
+		require.NoError(t, os.Lchown(file, uid, gid))
+	}
+
+	rc, err := CreateTarWithOptions(TarOptions{PreserveOwnership: true}, nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+	require.Contains(t, headers, "file")
+	assert.Equal(t, uid, headers["file"].Uid)
+	assert.Equal(t, gid, headers["file"].Gid)
+
+	rc, err = CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	headers = readTar(t, rc)
+	require.Contains(t, headers, "file")
+	assert.Zero(t, headers["file"].Uid)
+	assert.Zero(t, headers["file"].Gid)
+}
+
+func TestCreateTarWithOptionsIDMap(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file ownership is not available on Windows")
+	}
+
+	contextDir := t.TempDir()
+	file := filepath.Join(contextDir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("content"), 0o644))
+
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid == 0 {
+		uid, gid = 1234, 1234
+		require.NoError(t, os.Lchown(file, uid, gid))
+	}
+
+	idMap := idtools.NewIDMappingsFromMaps(
+		[]idtools.IDMap{{ContainerID: 1000, HostID: uid, Size: 1}},
+		[]idtools.IDMap{{ContainerID: 1000, HostID: gid, Size: 1}},
+	)
+	rc, err := CreateTarWithOptions(TarOptions{IDMap: idMap}, nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+	require.Contains(t, headers, "file")
+	assert.Equal(t, 1000, headers["file"].Uid)
+	assert.Equal(t, 1000, headers["file"].Gid)
+
+	unmapped := idtools.NewIDMappingsFromMaps(
+		[]idtools.IDMap{{ContainerID: 0, HostID: uid + 1, Size: 1}},
+		[]idtools.IDMap{{ContainerID: 0, HostID: gid + 1, Size: 1}},
+	)
+	rc, err = CreateTarWithOptions(TarOptions{IDMap: unmapped}, nil, contextDir)
+	require.NoError(t, err)
+	defer rc.Close()
+	_, err = io.Copy(io.Discard, rc)
+	assert.ErrorContains(t, err, "mapping the ownership of file")
+}
+
+func TestCreateTarWithOptionsReadIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		ignoreFile string
+		content    string
+		included   []string
+		excluded   []string
+	}{
+		{
+			name:       "containerignore",
+			ignoreFile: ".containerignore",
+			content:    "# comment\n*.txt\n!keep.txt\n",
+			included:   []string{"keep.txt", "Containerfile"},
+			excluded:   []string{"drop.txt", ".containerignore"},
+		},
+		{
+			name:       "dockerignore",
+			ignoreFile: ".dockerignore",
+			content:    "drop.txt\n",
+			included:   []string{"keep.txt", "Containerfile"},
+			excluded:   []string{"drop.txt", ".dockerignore"},
+		},
+		{
+			name:       "ignore file re-included",
+			ignoreFile: ".containerignore",
+			content:    "*.txt\n!.containerignore\n",
+			included:   []string{".containerignore", "Containerfile"},
+			excluded:   []string{"keep.txt", "drop.txt"},
+		},
+		{
+			name:     "no ignore file",
+			included: []string{"keep.txt", "drop.txt", "Containerfile"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contextDir := t.TempDir()
+			for _, f := range []string{"keep.txt", "drop.txt", "Containerfile"} {
+				require.NoError(t, os.WriteFile(filepath.Join(contextDir, f), []byte(f), 0o644))
+			}
+			if tt.ignoreFile != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(contextDir, tt.ignoreFile), []byte(tt.content), 0o644))
+			}
+
+			rc, err := CreateTarWithOptions(TarOptions{ReadIgnoreFile: true}, nil, contextDir)
+			require.NoError(t, err)
+			headers := readTar(t, rc)
+			for _, name := range tt.included {
+				assert.Contains(t, headers, name)
+			}
+			for _, name := range tt.excluded {
+				assert.NotContains(t, headers, name)
+			}
+		})
+	}
+}
+
+func TestCreateTarHardLinkAcrossSources(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("absolute source names are not portable to Windows")
+	}
+
+	contextDir := t.TempDir()
+	containerfile := filepath.Join(contextDir, "Containerfile")
+	require.NoError(t, os.WriteFile(containerfile, []byte("FROM scratch\n"), 0o644))
+
+	rc, err := CreateTar(nil, contextDir, containerfile)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+
+	require.Contains(t, headers, "Containerfile")
+	assert.Equal(t, byte(tar.TypeReg), headers["Containerfile"].Typeflag)
+	require.Contains(t, headers, filepath.ToSlash(containerfile))
+	extra := headers[filepath.ToSlash(containerfile)]
+	assert.Equal(t, byte(tar.TypeLink), extra.Typeflag)
+	assert.Equal(t, "Containerfile", extra.Linkname)
+}
+
+func TestCreateTarDirectorySources(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("absolute source names are not portable to Windows")
+	}
+
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "Containerfile"), []byte("FROM scratch\n"), 0o644))
+	extraDir := t.TempDir()
+	emptyDir := filepath.Join(extraDir, "mnt")
+	require.NoError(t, os.Mkdir(emptyDir, 0o755))
+	treeDir := filepath.Join(extraDir, "tree")
+	require.NoError(t, os.MkdirAll(filepath.Join(treeDir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(treeDir, "sub", "file"), []byte("data"), 0o644))
+
+	rc, err := CreateTar(nil, contextDir, emptyDir, treeDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+
+	require.Contains(t, headers, filepath.ToSlash(emptyDir))
+	assert.Equal(t, byte(tar.TypeDir), headers[filepath.ToSlash(emptyDir)].Typeflag)
+	require.Contains(t, headers, filepath.ToSlash(treeDir))
+	assert.Equal(t, byte(tar.TypeDir), headers[filepath.ToSlash(treeDir)].Typeflag)
+	assert.Contains(t, headers, filepath.ToSlash(filepath.Join(treeDir, "sub")))
+	assert.Contains(t, headers, filepath.ToSlash(filepath.Join(treeDir, "sub", "file")))
+	assert.Contains(t, headers, "Containerfile")
+}
+
+func TestCreateTarSymlinkLoop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires extra privileges on Windows")
+	}
+
+	contextDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(contextDir, "sub", "dir"), 0o755))
+	require.NoError(t, os.Symlink(".", filepath.Join(contextDir, "self")))
+	require.NoError(t, os.Symlink("..", filepath.Join(contextDir, "sub", "dir", "parent")))
+	require.NoError(t, os.Symlink("sub/dir", filepath.Join(contextDir, "shortcut")))
+
+	rc, err := CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+
+	assert.NotContains(t, headers, "self")
+	assert.NotContains(t, headers, "sub/dir/parent")
+	require.Contains(t, headers, "shortcut")
+	assert.Equal(t, "sub/dir", headers["shortcut"].Linkname)
+}
+
+func TestCreateTarWithOptionsCompressionLevel(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "data"), bytes.Repeat([]byte("podman "), 64*1024), 0o644))
+
+	size := func(level int) int {
+		rc, err := CreateTarWithOptions(TarOptions{CompressionLevel: level}, nil, contextDir)
+		require.NoError(t, err)
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		headers := readTar(t, io.NopCloser(bytes.NewReader(content)))
+		assert.Contains(t, headers, "data", "level %d", level)
+		return len(content)
+	}
+
+	assert.Less(t, size(gzip.BestCompression), size(gzip.HuffmanOnly))
+	// Out of range levels fall back to the default one.
+	assert.Equal(t, size(0), size(42))
+	assert.Equal(t, size(0), size(-3))
+}
+
+func TestCreateTarWithOptionsUncompressed(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "Containerfile"), []byte("FROM scratch\n"), 0o644))
+
+	rc, err := CreateTarWithOptions(TarOptions{Uncompressed: true}, nil, contextDir)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "Containerfile", hdr.Name)
+	_, err = tr.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCreateTarWithOptionsDeterministic(t *testing.T) {
+	createContext := func(mtime time.Time) string {
+		contextDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(contextDir, "b"), 0o755))
+		for _, name := range []string{"c", "a", "b/d"} {
+			file := filepath.Join(contextDir, name)
+			require.NoError(t, os.WriteFile(file, []byte(name), 0o644))
+			require.NoError(t, os.Chtimes(file, mtime, mtime))
+		}
+		return contextDir
+	}
+	readAll := func(contextDir string) []byte {
+		rc, err := CreateTarWithOptions(TarOptions{Deterministic: true}, nil, contextDir)
+		require.NoError(t, err)
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return content
+	}
+
+	first := readAll(createContext(time.Now()))
+	second := readAll(createContext(time.Now().Add(-time.Hour)))
+	assert.Equal(t, first, second)
+
+	gr, err := gzip.NewReader(bytes.NewReader(first))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		assert.Equal(t, int64(0), hdr.ModTime.Unix(), hdr.Name)
+		assert.Empty(t, hdr.Uname, hdr.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "b/d", "c"}, names)
+}
+
+func TestCreateTarExcludeAbsoluteSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("absolute source names are not portable to Windows")
+	}
+
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "file"), []byte("content"), 0o644))
+	outside := t.TempDir()
+	kept := filepath.Join(outside, "Containerfile")
+	dropped := filepath.Join(outside, "Containerfile.dev")
+	for _, f := range []string{kept, dropped} {
+		require.NoError(t, os.WriteFile(f, []byte("FROM scratch\n"), 0o644))
+	}
+
+	// Relative patterns never apply to the sources out of the context.
+	rc, err := CreateTar([]string{"*", "!file"}, contextDir, kept, dropped)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+	assert.Contains(t, headers, "file")
+	assert.Contains(t, headers, kept)
+	assert.Contains(t, headers, dropped)
+
+	rc, err = CreateTar([]string{filepath.Join(outside, "*"), "!" + kept}, contextDir, kept, dropped)
+	require.NoError(t, err)
+	headers = readTar(t, rc)
+	assert.Contains(t, headers, "file")
+	assert.Contains(t, headers, kept)
+	assert.NotContains(t, headers, dropped)
+}
+
+func TestCreateTarWithProgress(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(contextDir, "dir"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "a"), []byte("12345"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "dir", "b"), []byte("123"), 0o644))
+
+	type call struct {
+		path  string
+		bytes int64
+	}
+	var calls []call
+	rc, err := CreateTarWithProgress(func(path string, bytes int64) {
+		calls = append(calls, call{path, bytes})
+	}, nil, contextDir)
+	require.NoError(t, err)
+	readTar(t, rc)
+
+	assert.Equal(t, []call{{"a", 5}, {"dir/b", 8}}, calls)
+}
+
+func TestCreateTarSourceRemovedDuringWalk(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "a"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "b"), []byte("b"), 0o644))
+
+	// Remove b once a is written, b is already listed by the walk.
+	rc, err := CreateTarWithProgress(func(path string, _ int64) {
+		if path == "a" {
+			assert.NoError(t, os.Remove(filepath.Join(contextDir, "b")))
+		}
+	}, nil, contextDir)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	_, err = io.ReadAll(rc)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCreateTarContextCanceled(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "a"), []byte("a"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rc, err := CreateTarContext(ctx, nil, contextDir, filepath.Join(contextDir, "a"))
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(rc)
+	assert.ErrorIs(t, err, context.Canceled)
+	err = rc.Close()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCreateTarWithOptionsFollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires extra privileges on Windows")
+	}
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "data"), []byte("data"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(outside, "back")))
+
+	contextDir := t.TempDir()
+	require.NoError(t, os.Symlink(filepath.Join(outside, "data"), filepath.Join(contextDir, "file")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(contextDir, "dir")))
+	require.NoError(t, os.Symlink(".", filepath.Join(contextDir, "self")))
+	require.NoError(t, os.Symlink("missing", filepath.Join(contextDir, "dangling")))
+
+	rc, err := CreateTarWithOptions(TarOptions{FollowSymlinks: true}, nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+
+	require.Contains(t, headers, "file")
+	assert.Equal(t, byte(tar.TypeReg), headers["file"].Typeflag)
+	assert.Equal(t, int64(len("data")), headers["file"].Size)
+	require.Contains(t, headers, "dir")
+	assert.Equal(t, byte(tar.TypeDir), headers["dir"].Typeflag)
+	require.Contains(t, headers, "dir/data")
+	assert.Equal(t, byte(tar.TypeReg), headers["dir/data"].Typeflag)
+	assert.NotContains(t, headers, "dir/back")
+	assert.NotContains(t, headers, "self")
+	require.Contains(t, headers, "dangling")
+	assert.Equal(t, byte(tar.TypeSymlink), headers["dangling"].Typeflag)
+
+	rc, err = CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	headers = readTar(t, rc)
+	assert.Equal(t, byte(tar.TypeSymlink), headers["file"].Typeflag)
+	assert.Equal(t, byte(tar.TypeSymlink), headers["dir"].Typeflag)
+}
+
+func TestDryRunTar(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(contextDir, "foo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "foo", "Containerfile"), []byte("FROM scratch\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "foo", "notes.md"), []byte("notes"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "excluded.txt"), []byte("excluded"), 0o644))
+	excludes := []string{"excluded.txt", "**/*.md"}
+
+	included, excluded, err := DryRunTar(excludes, contextDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "foo/Containerfile"}, included)
+	assert.Equal(t, []string{"excluded.txt", "foo/notes.md"}, excluded)
+
+	// the dry run keeps the same entries as the tar
+	rc, err := CreateTar(excludes, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+	assert.Len(t, headers, len(included))
+	for _, name := range included {
+		assert.Contains(t, headers, name)
+	}
+
+	_, _, err = DryRunTar(nil)
+	assert.Error(t, err)
+}
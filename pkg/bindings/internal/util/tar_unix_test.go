@@ -0,0 +1,94 @@
+//go:build !windows
+
+package util
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.podman.io/storage/pkg/archive"
+	"go.podman.io/storage/pkg/system"
+	"golang.org/x/sys/unix"
+)
+
+func TestCreateTarWarnsOnUnsupportedFileType(t *testing.T) {
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "file"), []byte("content"), 0o644))
+	fifo := filepath.Join(contextDir, "fifo")
+	require.NoError(t, unix.Mkfifo(fifo, 0o644))
+
+	var logs bytes.Buffer
+	logrus.SetOutput(&logs)
+	defer logrus.SetOutput(os.Stderr)
+
+	rc, err := CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+
+	assert.Contains(t, headers, "file")
+	assert.NotContains(t, headers, "fifo")
+	assert.Contains(t, logs.String(), "Skipping "+fifo+": unsupported file type p---------")
+}
+
+func TestCreateTarWithOptionsPreserveXattrs(t *testing.T) {
+	contextDir := t.TempDir()
+	file := filepath.Join(contextDir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("content"), 0o644))
+	if err := system.Lsetxattr(file, "user.podman.test", []byte("value"), 0); err != nil {
+		t.Skipf("cannot set extended attributes in the temporary directory: %v", err)
+	}
+
+	rc, err := CreateTarWithOptions(TarOptions{PreserveXattrs: true}, nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+	require.Contains(t, headers, "file")
+	assert.Equal(t, "value", headers["file"].PAXRecords["SCHILY.xattr.user.podman.test"])
+
+	rc, err = CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	headers = readTar(t, rc)
+	require.Contains(t, headers, "file")
+	assert.NotContains(t, headers["file"].PAXRecords, "SCHILY.xattr.user.podman.test")
+}
+
+func TestCreateTarPreservesSpecialModeBits(t *testing.T) {
+	contextDir := t.TempDir()
+	modes := map[string]os.FileMode{
+		"setuid": 0o755 | os.ModeSetuid,
+		"setgid": 0o755 | os.ModeSetgid,
+		"sticky": 0o777 | os.ModeSticky | os.ModeDir,
+	}
+	for name, mode := range modes {
+		path := filepath.Join(contextDir, name)
+		if mode.IsDir() {
+			require.NoError(t, os.Mkdir(path, 0o755))
+		} else {
+			require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+		}
+		// chmod, unlike the umask limited creation, sets the special bits
+		require.NoError(t, os.Chmod(path, mode))
+	}
+
+	rc, err := CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	headers := readTar(t, rc)
+	assert.Equal(t, int64(0o4755), headers["setuid"].Mode)
+	assert.Equal(t, int64(0o2755), headers["setgid"].Mode)
+	assert.Equal(t, int64(0o1777), headers["sticky"].Mode)
+
+	rc, err = CreateTar(nil, contextDir)
+	require.NoError(t, err)
+	defer rc.Close()
+	dest := t.TempDir()
+	require.NoError(t, archive.Untar(rc, dest, &archive.TarOptions{NoLchown: true}))
+	for name, mode := range modes {
+		info, err := os.Stat(filepath.Join(dest, name))
+		require.NoError(t, err)
+		assert.Equal(t, mode, info.Mode(), name)
+	}
+}
@@ -1,21 +1,39 @@
 package kube
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/containers/podman/v5/pkg/auth"
 	"github.com/containers/podman/v5/pkg/bindings"
 	"github.com/containers/podman/v5/pkg/bindings/generate"
+	"github.com/containers/podman/v5/pkg/bindings/images"
 	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
 	"github.com/sirupsen/logrus"
+	imageAuth "go.podman.io/image/v5/pkg/docker/config"
 	"go.podman.io/image/v5/types"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
+// ErrPlayTimeout is returned together with a partial report when the service
+// gave up on a play request after the requested timeout.
+var ErrPlayTimeout = errors.New("kube play timed out")
+
 func Play(ctx context.Context, path string, options *PlayOptions) (*entitiesTypes.KubePlayReport, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -26,8 +44,250 @@ func Play(ctx context.Context, path string, options *PlayOptions) (*entitiesType
 	return PlayWithBody(ctx, f, options)
 }
 
+// PlayPaths plays the kube YAML files at paths as a single multi-document
+// YAML, in the order of paths.
+func PlayPaths(ctx context.Context, paths []string, options *PlayOptions) (*entitiesTypes.KubePlayReport, error) {
+	files := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		files = append(files, f)
+	}
+
+	return PlayWithBody(ctx, joinYAMLDocuments(files), options)
+}
+
+// joinYAMLDocuments returns a reader of the YAML streams of readers separated
+// by document markers. A stream not ending with a newline is still separated.
+func joinYAMLDocuments(readers []io.Reader) io.Reader {
+	joined := make([]io.Reader, 0, 2*len(readers))
+	for i, r := range readers {
+		if i > 0 {
+			joined = append(joined, strings.NewReader("\n---\n"))
+		}
+		joined = append(joined, r)
+	}
+	return io.MultiReader(joined...)
+}
+
+// playURLTimeout bounds the download of the kube YAML by PlayFromURL.
+const playURLTimeout = 5 * time.Minute
+
+// maxPlayURLSize is the size of the largest kube YAML PlayFromURL downloads.
+const maxPlayURLSize = 32 << 20
+
+// errPlayURLTooLarge is returned when the kube YAML published at a URL is
+// larger than maxPlayURLSize.
+var errPlayURLTooLarge = errors.New("kube YAML exceeds the maximum size")
+
+// playURLBody fails once more than maxPlayURLSize bytes have been read,
+// rather than playing a truncated kube YAML.
+type playURLBody struct {
+	r *io.LimitedReader
+}
+
+func newPlayURLBody(r io.Reader) *playURLBody {
+	return &playURLBody{r: &io.LimitedReader{R: r, N: maxPlayURLSize + 1}}
+}
+
+func (b *playURLBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if b.r.N <= 0 {
+		return n, fmt.Errorf("%w of %d bytes", errPlayURLTooLarge, maxPlayURLSize)
+	}
+	return n, err
+}
+
+// PlayFromURL downloads the kube YAML published at url and plays it. The
+// manifest is streamed to the service rather than buffered in memory.
+func PlayFromURL(ctx context.Context, url string, options *PlayOptions) (*entitiesTypes.KubePlayReport, error) {
+	if options == nil {
+		options = new(PlayOptions)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: playURLTimeout}
+	if options.GetSkipTLSVerify() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+		client.Transport = transport
+	}
+	response, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching kube YAML from %s: %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching kube YAML from %s: unexpected status %s", url, response.Status)
+	}
+	if response.ContentLength > maxPlayURLSize {
+		return nil, fmt.Errorf("fetching kube YAML from %s: %w of %d bytes", url, errPlayURLTooLarge, maxPlayURLSize)
+	}
+
+	return PlayWithBody(ctx, newPlayURLBody(response.Body), options)
+}
+
 func PlayWithBody(ctx context.Context, body io.Reader, options *PlayOptions) (*entitiesTypes.KubePlayReport, error) {
+	return playWithContentType(ctx, body, options, "")
+}
+
+// PlayWithTar plays the kube YAML stored as play.yaml at the root of the,
+// possibly gzip compressed, tar stream along with the build contexts it
+// holds. The stream is sent as is, it is only read up to play.yaml to make
+// sure it is present. ConfigMaps must be part of play.yaml. A stream larger
+// than UploadChunkSize is uploaded in chunks before being played.
+func PlayWithTar(ctx context.Context, tarStream io.Reader, options *PlayOptions) (*entitiesTypes.KubePlayReport, error) {
+	if options == nil {
+		options = new(PlayOptions)
+	}
+	if options.ConfigMaps != nil || options.ConfigMapReaders != nil {
+		return nil, errors.New("configmaps cannot be added to a tar stream, include them in play.yaml")
+	}
+	body, err := checkPlayTar(tarStream)
+	if err != nil {
+		return nil, err
+	}
+	if chunkSize := options.GetUploadChunkSize(); chunkSize > 0 {
+		chunk := make([]byte, chunkSize)
+		n, err := io.ReadFull(body, chunk)
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			// the stream fits in a single chunk, send it as is
+			body = bytes.NewReader(chunk[:n])
+		case err != nil:
+			return nil, fmt.Errorf("reading tar stream: %w", err)
+		default:
+			id, err := uploadPlayTar(ctx, io.MultiReader(bytes.NewReader(chunk), body), chunk)
+			if err != nil {
+				return nil, err
+			}
+			uploaded := *options
+			uploaded.Upload = &id
+			return playWithContentType(ctx, http.NoBody, &uploaded, "application/x-tar")
+		}
+	}
+	return playWithContentType(ctx, body, options, "application/x-tar")
+}
+
+// checkPlayTar reads the tar stream r up to its play.yaml file and returns a
+// reader of the whole stream, replaying what was read.
+func checkPlayTar(r io.Reader) (io.Reader, error) {
+	var read bytes.Buffer
+	br := bufio.NewReader(io.TeeReader(r, &read))
+	var tr *tar.Reader
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading tar stream: %w", err)
+		}
+		tr = tar.NewReader(gr)
+	} else {
+		tr = tar.NewReader(br)
+	}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("tar stream does not contain a play.yaml file")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar stream: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Clean(hdr.Name) == "play.yaml" {
+			return io.MultiReader(&read, r), nil
+		}
+	}
+}
+
+// playWithContentType sends body, of the given content type or YAML when
+// empty, to the play endpoint and returns its report.
+func playWithContentType(ctx context.Context, body io.Reader, options *PlayOptions, contentType string) (*entitiesTypes.KubePlayReport, error) {
 	var report entitiesTypes.KubePlayReport
+	response, err := playRequest(ctx, body, options, false, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusGatewayTimeout {
+		if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+			return nil, fmt.Errorf("%w: decoding report: %v", ErrPlayTimeout, err)
+		}
+		return &report, ErrPlayTimeout
+	}
+	if err := response.Process(&report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// PlayWithBodyStream behaves like PlayWithBody and forwards the image pull
+// and build progress to events as the service reports it. The final report
+// is sent as the last event and returned. events is closed on return.
+func PlayWithBodyStream(ctx context.Context, body io.Reader, options *PlayOptions, events chan<- KubePlayEvent) (*entitiesTypes.KubePlayReport, error) {
+	defer close(events)
+
+	response, err := playRequest(ctx, body, options, true, "")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if !response.IsSuccess() {
+		return nil, response.Process(nil)
+	}
+	return decodePlayStream(ctx, response.Body, events)
+}
+
+// decodePlayStream forwards the messages of the play progress stream to events
+// until the final report.
+func decodePlayStream(ctx context.Context, stream io.Reader, events chan<- KubePlayEvent) (*entitiesTypes.KubePlayReport, error) {
+	dec := json.NewDecoder(stream)
+	for {
+		var s images.BuildResponse
+		if err := dec.Decode(&s); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("kube play stream ended without a report")
+			}
+			return nil, fmt.Errorf("decoding stream: %w", err)
+		}
+
+		switch {
+		case s.Error != nil:
+			return nil, errors.New(s.Error.Message)
+		case s.ErrorMessage != "":
+			return nil, errors.New(s.ErrorMessage)
+		case s.Aux != nil:
+			var report entitiesTypes.KubePlayReport
+			if err := json.Unmarshal(s.Aux, &report); err != nil {
+				return nil, fmt.Errorf("decoding report: %w", err)
+			}
+			select {
+			case events <- KubePlayEvent{Report: &report}:
+			case <-ctx.Done():
+			}
+			return &report, nil
+		default:
+			select {
+			case events <- KubePlayEvent{Stream: s.Stream}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+}
+
+// playRequest sends body and options to the play endpoint, asking for the
+// progress stream when stream is set. A body with a contentType is sent as is,
+// without the ConfigMaps. The caller must close the response body.
+func playRequest(ctx context.Context, body io.Reader, options *PlayOptions, stream bool, contentType string) (*bindings.APIResponse, error) {
 	if options == nil {
 		options = new(PlayOptions)
 	}
@@ -41,6 +301,9 @@ func PlayWithBody(ctx context.Context, body io.Reader, options *PlayOptions) (*e
 	if err != nil {
 		return nil, err
 	}
+	if stream {
+		params.Set("stream", "true")
+	}
 	// SkipTLSVerify is special.  It's not being serialized by ToParams()
 	// because we need to flip the boolean.
 	if options.SkipTLSVerify != nil {
@@ -49,43 +312,147 @@ func PlayWithBody(ctx context.Context, body io.Reader, options *PlayOptions) (*e
 	if options.Start != nil {
 		params.Set("start", strconv.FormatBool(options.GetStart()))
 	}
+	// A dry run must never start containers, even against a server that
+	// does not know about the dryRun parameter.
+	if options.GetDryRun() {
+		params.Set("start", "false")
+	}
 
 	// For the remote case, read any configMaps passed and append it to the main yaml content
-	if options.ConfigMaps != nil {
+	if contentType == "" && (options.ConfigMaps != nil || options.ConfigMapReaders != nil) {
 		yamlBytes, err := io.ReadAll(body)
 		if err != nil {
 			return nil, err
 		}
-
-		for _, cm := range *options.ConfigMaps {
+		appendConfigMap := func(name string, cmBytes []byte) error {
+			if err := checkConfigMapKinds(name, cmBytes); err != nil {
+				return err
+			}
 			// Add kube yaml splitter
 			yamlBytes = append(yamlBytes, []byte("---\n")...)
+			cmBytes = append(cmBytes, []byte("\n")...)
+			yamlBytes = append(yamlBytes, cmBytes...)
+			return nil
+		}
+
+		var cmFiles []string
+		if options.ConfigMaps != nil {
+			cmFiles, err = configMapFiles(*options.ConfigMaps)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, cm := range cmFiles {
 			cmBytes, err := os.ReadFile(cm)
 			if err != nil {
 				return nil, err
 			}
-			cmBytes = append(cmBytes, []byte("\n")...)
-			yamlBytes = append(yamlBytes, cmBytes...)
+			if err := appendConfigMap(cm, cmBytes); err != nil {
+				return nil, err
+			}
+		}
+		for i, r := range options.GetConfigMapReaders() {
+			name := fmt.Sprintf("#%d", i)
+			cmBytes, err := io.ReadAll(r)
+			if err != nil {
+				return nil, fmt.Errorf("reading configmap %s: %w", name, err)
+			}
+			if err := appendConfigMap(name, cmBytes); err != nil {
+				return nil, err
+			}
 		}
 		body = io.NopCloser(bytes.NewReader(yamlBytes))
 	}
 
-	header, err := auth.MakeXRegistryAuthHeader(&types.SystemContext{AuthFilePath: options.GetAuthfile()}, options.GetUsername(), options.GetPassword())
+	header, err := playAuthHeader(options.GetAuthfile(), options.GetUsername(), options.GetPassword())
 	if err != nil {
 		return nil, err
 	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if allowed := options.GetAllowedDigests(); len(allowed) > 0 {
+		value, err := json.Marshal(allowed)
+		if err != nil {
+			return nil, err
+		}
+		header.Set("X-Allowed-Digests", string(value))
+	}
 
-	response, err := conn.DoRequest(ctx, body, http.MethodPost, "/play/kube", params, header)
-	if err != nil {
-		return nil, err
+	return conn.DoRequest(ctx, body, http.MethodPost, "/play/kube", params, header)
+}
+
+// playAuthHeader returns the X-Registry-Config header when the authfile holds
+// the credentials of several registries, so the server authenticates to each
+// of them independently, and the X-Registry-Auth header otherwise.
+func playAuthHeader(authfile, username, password string) (http.Header, error) {
+	sys := &types.SystemContext{AuthFilePath: authfile}
+	if authfile != "" {
+		authConfigs, err := imageAuth.GetAllCredentials(sys)
+		if err != nil {
+			return nil, err
+		}
+		if len(authConfigs) > 1 {
+			return auth.MakeXRegistryConfigHeader(sys, username, password)
+		}
 	}
-	defer response.Body.Close()
+	return auth.MakeXRegistryAuthHeader(sys, username, password)
+}
 
-	if err := response.Process(&report); err != nil {
-		return nil, err
+// checkConfigMapKinds makes sure every document of the configmap file at path
+// is of kind ConfigMap.
+func checkConfigMapKinds(path string, content []byte) error {
+	d := yamlv3.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc map[string]any
+		err := d.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parsing configmap file %s: %w", path, err)
+		}
+		if doc == nil {
+			continue
+		}
+		if kind, _ := doc["kind"].(string); kind != "ConfigMap" {
+			return fmt.Errorf("configmap file %s contains an object of kind %q, only ConfigMap is allowed", path, kind)
+		}
 	}
+}
 
-	return &report, nil
+// configMapFiles expands the directories in paths to the YAML files they
+// contain, in lexical order. Other files in these directories are skipped.
+func configMapFiles(paths []string) ([]string, error) {
+	files := make([]string, 0, len(paths))
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				logrus.Debugf("Skipping directory %s in configmap directory %s", name, p)
+				continue
+			}
+			switch filepath.Ext(name) {
+			case ".yaml", ".yml":
+				files = append(files, filepath.Join(p, name))
+			default:
+				logrus.Debugf("Skipping non-YAML file %s in configmap directory %s", name, p)
+			}
+		}
+	}
+	return files, nil
 }
 
 func Down(ctx context.Context, path string, options DownOptions) (*entitiesTypes.KubePlayReport, error) {
@@ -129,10 +496,10 @@ func Generate(ctx context.Context, nameOrIDs []string, options generate.KubeOpti
 	return generate.Kube(ctx, nameOrIDs, &options)
 }
 
-func Apply(ctx context.Context, path string, options *ApplyOptions) error {
+func Apply(ctx context.Context, path string, options *ApplyOptions) (*entitiesTypes.KubeApplyReport, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -143,26 +510,51 @@ func Apply(ctx context.Context, path string, options *ApplyOptions) error {
 	return ApplyWithBody(ctx, f, options)
 }
 
-func ApplyWithBody(ctx context.Context, body io.Reader, options *ApplyOptions) error {
+func ApplyWithBody(ctx context.Context, body io.Reader, options *ApplyOptions) (*entitiesTypes.KubeApplyReport, error) {
+	var report entitiesTypes.KubeApplyReport
 	if options == nil {
 		options = new(ApplyOptions)
 	}
 
+	if err := checkApplyFiles(options.GetKubeconfig(), options.GetCACertFile()); err != nil {
+		return nil, err
+	}
+
 	conn, err := bindings.GetClient(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	params, err := options.ToParams()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	response, err := conn.DoRequest(ctx, body, http.MethodPost, "/kube/apply", params, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer response.Body.Close()
 
+	if err := response.Process(&report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// checkApplyFiles makes sure the kubeconfig and CA cert files exist before
+// contacting the service. A CA cert file of "insecure" is not a path.
+func checkApplyFiles(kubeconfig, caCertFile string) error {
+	if kubeconfig != "" {
+		if _, err := os.Stat(kubeconfig); err != nil {
+			return fmt.Errorf("invalid kubeconfig: %w", err)
+		}
+	}
+	if caCertFile != "" && !strings.EqualFold(caCertFile, "insecure") {
+		if _, err := os.Stat(caCertFile); err != nil {
+			return fmt.Errorf("invalid CA cert file: %w", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,197 @@
+package kube
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigMapFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.yml", "a.yaml", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.yaml"), 0o755))
+	single := filepath.Join(t.TempDir(), "cm.yaml")
+	require.NoError(t, os.WriteFile(single, nil, 0o644))
+
+	files, err := configMapFiles([]string{single, dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{single, filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yml")}, files)
+
+	_, err = configMapFiles([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
+
+func TestCheckConfigMapKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "single configmap",
+			content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n",
+		},
+		{
+			name:    "multiple configmaps",
+			content: "---\nkind: ConfigMap\n---\n---\nkind: ConfigMap\n",
+		},
+		{
+			name:    "deployment",
+			content: "kind: ConfigMap\n---\napiVersion: apps/v1\nkind: Deployment\n",
+			err:     `configmap file cm.yaml contains an object of kind "Deployment", only ConfigMap is allowed`,
+		},
+		{
+			name:    "missing kind",
+			content: "metadata:\n  name: foo\n",
+			err:     `configmap file cm.yaml contains an object of kind "", only ConfigMap is allowed`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConfigMapKinds("cm.yaml", []byte(tt.content))
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPlayAuthHeader(t *testing.T) {
+	dir := t.TempDir()
+	// Keep the credentials of the user out of the way.
+	t.Setenv("HOME", dir)
+	t.Setenv("DOCKER_CONFIG", dir)
+	writeAuthfile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	// "user:pass" base64 encoded
+	single := writeAuthfile("single.json", `{"auths":{"quay.io":{"auth":"dXNlcjpwYXNz"}}}`)
+	multi := writeAuthfile("multi.json", `{"auths":{"quay.io":{"auth":"dXNlcjpwYXNz"},"docker.io":{"auth":"dXNlcjpwYXNz"}}}`)
+
+	header, err := playAuthHeader(single, "", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, header.Get("X-Registry-Auth"))
+	assert.Empty(t, header.Get("X-Registry-Config"))
+
+	header, err = playAuthHeader(multi, "", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, header.Get("X-Registry-Config"))
+	assert.Empty(t, header.Get("X-Registry-Auth"))
+}
+
+func TestDecodePlayStream(t *testing.T) {
+	collect := func(stream string) ([]KubePlayEvent, *entitiesTypes.KubePlayReport, error) {
+		events := make(chan KubePlayEvent, 10)
+		report, err := decodePlayStream(context.Background(), strings.NewReader(stream), events)
+		close(events)
+		var received []KubePlayEvent
+		for e := range events {
+			received = append(received, e)
+		}
+		return received, report, err
+	}
+
+	events, report, err := collect(`{"stream":"Pulling\n"}
+{"stream":"Done\n"}
+{"aux":{"Pods":[{"ID":"1234"}]}}
+`)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "Pulling\n", events[0].Stream)
+	assert.Equal(t, "Done\n", events[1].Stream)
+	assert.Equal(t, report, events[2].Report)
+	require.Len(t, report.Pods, 1)
+	assert.Equal(t, "1234", report.Pods[0].ID)
+
+	events, _, err = collect(`{"stream":"Pulling\n"}
+{"error":"playing YAML file: boom","errorDetail":{"message":"playing YAML file: boom"}}
+`)
+	assert.EqualError(t, err, "playing YAML file: boom")
+	assert.Len(t, events, 1)
+
+	_, _, err = collect(`{"stream":"Pulling\n"}`)
+	assert.EqualError(t, err, "kube play stream ended without a report")
+}
+
+func TestCheckPlayTar(t *testing.T) {
+	newTar := func(compress bool, names ...string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser = nopCloser{&buf}
+		if compress {
+			w = gzip.NewWriter(&buf)
+		}
+		tw := tar.NewWriter(w)
+		for _, name := range names {
+			content := []byte(strings.Repeat("x", 1024))
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content))}))
+			_, err := tw.Write(content)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	for _, compress := range []bool{false, true} {
+		stream := newTar(compress, "foobar/Containerfile", "./play.yaml", "other")
+		body, err := checkPlayTar(bytes.NewReader(stream))
+		require.NoError(t, err)
+		sent, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, stream, sent)
+
+		_, err = checkPlayTar(bytes.NewReader(newTar(compress, "foobar/play.yaml")))
+		assert.ErrorContains(t, err, "does not contain a play.yaml")
+	}
+
+	_, err := checkPlayTar(strings.NewReader("kind: Pod\n"))
+	assert.ErrorContains(t, err, "reading tar stream")
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestJoinYAMLDocuments(t *testing.T) {
+	joined, err := io.ReadAll(joinYAMLDocuments([]io.Reader{
+		strings.NewReader("kind: ConfigMap\n"),
+		strings.NewReader("---\nkind: Pod"),
+		strings.NewReader("kind: Secret\n"),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ConfigMap\n\n---\n---\nkind: Pod\n---\nkind: Secret\n", string(joined))
+}
+
+func TestPlayURLBody(t *testing.T) {
+	body, err := io.ReadAll(newPlayURLBody(strings.NewReader("kind: Pod\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Pod\n", string(body))
+
+	_, err = io.ReadAll(newPlayURLBody(io.LimitReader(zeroReader{}, maxPlayURLSize)))
+	assert.NoError(t, err)
+	_, err = io.ReadAll(newPlayURLBody(io.LimitReader(zeroReader{}, maxPlayURLSize+1)))
+	assert.ErrorIs(t, err, errPlayURLTooLarge)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -1,7 +1,10 @@
 package kube
 
 import (
+	"io"
 	"net"
+
+	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
 )
 
 // PlayOptions are optional options for replaying kube YAML files
@@ -24,7 +27,19 @@ type PlayOptions struct {
 	NoHostname *bool
 	// NoHosts - do not generate /etc/hosts file in pod's containers
 	NoHosts *bool
-	// Quiet - suppress output when pulling images.
+	// AddHost - add entries to the /etc/hosts file of the pods, in the
+	// host:ip format
+	AddHost *[]string
+	// Restart - restart the containers of the pods already played with the
+	// same spec and options, recreate the pods whose spec or options changed
+	Restart *bool
+	// LogLevel - log level of the messages of the service for this play,
+	// the image pulls and builds keep the level of the service
+	LogLevel *string
+	// Hostname - hostname of the pod, the YAML must describe a single pod
+	Hostname *string
+	// Quiet - suppress output when pulling images. The service defaults to
+	// quiet, except for the progress streamed by PlayWithBodyStream.
 	Quiet *bool
 	// SignaturePolicy - path to a signature-policy file.
 	SignaturePolicy *string
@@ -38,8 +53,12 @@ type PlayOptions struct {
 	StaticIPs *[]net.IP
 	// StaticMACs - Static MAC address used by the pod(s).
 	StaticMACs *[]net.HardwareAddr
-	// ConfigMaps - slice of pathnames to kubernetes configmap YAMLs.
+	// ConfigMaps - slice of pathnames to kubernetes configmap YAMLs or to
+	// directories holding them.
 	ConfigMaps *[]string
+	// ConfigMapReaders - kubernetes configmap YAMLs appended after the
+	// ones of ConfigMaps, for configmaps not stored in files.
+	ConfigMapReaders *[]io.Reader `schema:"-"`
 	// LogDriver for the container. For example: journald
 	LogDriver *string
 	// LogOptions for the container. For example: journald
@@ -64,6 +83,85 @@ type PlayOptions struct {
 	Wait             *bool
 	ServiceContainer *bool
 	NoPodPrefix      *bool
+	// DryRun - validate the YAML and report the resources that would be
+	// created without creating or starting anything
+	DryRun *bool
+	// Namespace - prefix the pod names with the namespace and label the
+	// pods with it, volumes and secrets are not namespaced
+	Namespace *string
+	// PullPolicy - pull policy applied to all images: always, missing,
+	// never or newer
+	PullPolicy *string
+	// CPULimit - maximum cpu limit of every container, lower limits set in
+	// the YAML win
+	CPULimit *string
+	// MemoryLimit - maximum memory limit of every container, lower limits
+	// set in the YAML win
+	MemoryLimit *string
+	// Retry - number of times to retry pulling an image in case of failure
+	Retry *uint
+	// RetryDelay - delay between pull retries, exponential backoff when unset
+	RetryDelay *string
+	// NamePrefix - prepended to the names of the pods, containers and
+	// volumes created
+	NamePrefix *string
+	// BuildArgs - build arguments passed to every image built from the
+	// context directory, they cannot be scoped to a single image
+	BuildArgs map[string]string
+	// NoCache - do not use cached layers when building the images from the
+	// context directory
+	NoCache *bool
+	// BuildParallelism - maximum number of images built at the same time
+	// from the context directory
+	BuildParallelism *uint
+	// Timeout - seconds after which the service gives up on the request and
+	// answers with a report of what was created so far
+	Timeout *uint
+	// Atomic - remove the pods, volumes and secrets created by the request
+	// when it fails
+	Atomic *bool
+	// SkipExisting - use the images present in the local storage without
+	// contacting a registry, missing images are still pulled
+	SkipExisting *bool
+	// AllowPrivileged - allow the containers asking for privileges, the
+	// service refuses the YAML otherwise
+	AllowPrivileged *bool
+	// RequireDigest - refuse the YAML when one of its images is not
+	// referenced by digest
+	RequireDigest *bool
+	// AllowedDigests - digest the images, as written in the YAML, must
+	// resolve to
+	AllowedDigests map[string]string `schema:"-"`
+	// WaitReady - wait for the started containers to be healthy, or running
+	// or exited successfully when they have no healthcheck, use Timeout to
+	// bound the wait
+	WaitReady *bool
+	// EnvFile - file of the context tar holding KEY=VALUE lines to set in
+	// each container, unless the YAML defines them
+	EnvFile *string
+	// CgroupParent - parent cgroup of the pods and their containers
+	CgroupParent *string
+	// Sysctl - name=value kernel parameters set in every pod, replacing the
+	// values of the YAML
+	Sysctl []string
+	// DNSServer - name servers of the pods, replacing those of the YAML
+	DNSServer []string
+	// DNSSearch - search domains of the pods, replacing those of the YAML
+	DNSSearch []string
+	// DNSOption - resolver options of the pods, replacing those of the YAML
+	DNSOption []string
+	// Validate - with strict, refuse the documents with unknown fields or
+	// values of the wrong type before playing them
+	Validate *string
+	// Mirror - registry to pull the images of fully-qualified references
+	// from instead of their own registry
+	Mirror *string
+	// Upload - ID of a context tar uploaded in chunks to play instead of
+	// the body
+	Upload *string
+	// UploadChunkSize - PlayWithTar uploads the tar stream in chunks of
+	// this size, resuming interrupted chunks, when it does not fit in one
+	UploadChunkSize *int64 `schema:"-"`
 }
 
 // ApplyOptions are optional options for applying kube YAML files to a k8s cluster
@@ -80,6 +178,12 @@ type ApplyOptions struct {
 	File *string
 	// Service - creates a service for the container being deployed.
 	Service *bool
+	// ServerSideApply - use Kubernetes server-side apply instead of
+	// creating the objects.
+	ServerSideApply *bool
+	// FieldManager - name of the manager owning the applied fields when
+	// using server-side apply.
+	FieldManager *string
 }
 
 // DownOptions are optional options for tearing down kube YAML files to a k8s cluster
@@ -88,4 +192,23 @@ type ApplyOptions struct {
 type DownOptions struct {
 	// Force - remove volumes on --down
 	Force *bool
+	// Timeout - seconds to wait for the containers to stop before killing
+	// them, 0 uses the stop timeout of each container
+	Timeout *uint
+	// KeepVolumes - do not remove the volumes, even with Force
+	KeepVolumes *bool
+	// LabelSelector - only tear down the documents whose pods match this
+	// label selector
+	LabelSelector *string
+	// Namespace - only tear down the pods played in this namespace
+	Namespace *string
+}
+
+// KubePlayEvent is a message of the progress stream of PlayWithBodyStream,
+// only one of its fields is set.
+type KubePlayEvent struct {
+	// Stream - image pull and build progress output
+	Stream string
+	// Report - the final report, always the last event
+	Report *entitiesTypes.KubePlayReport
 }
@@ -91,3 +91,33 @@ func (o *ApplyOptions) GetService() bool {
 	}
 	return *o.Service
 }
+
+// WithServerSideApply set field ServerSideApply to given value
+func (o *ApplyOptions) WithServerSideApply(value bool) *ApplyOptions {
+	o.ServerSideApply = &value
+	return o
+}
+
+// GetServerSideApply returns value of field ServerSideApply
+func (o *ApplyOptions) GetServerSideApply() bool {
+	if o.ServerSideApply == nil {
+		var z bool
+		return z
+	}
+	return *o.ServerSideApply
+}
+
+// WithFieldManager set field FieldManager to given value
+func (o *ApplyOptions) WithFieldManager(value string) *ApplyOptions {
+	o.FieldManager = &value
+	return o
+}
+
+// GetFieldManager returns value of field FieldManager
+func (o *ApplyOptions) GetFieldManager() string {
+	if o.FieldManager == nil {
+		var z string
+		return z
+	}
+	return *o.FieldManager
+}
@@ -31,3 +31,63 @@ func (o *DownOptions) GetForce() bool {
 	}
 	return *o.Force
 }
+
+// WithTimeout set field Timeout to given value
+func (o *DownOptions) WithTimeout(value uint) *DownOptions {
+	o.Timeout = &value
+	return o
+}
+
+// GetTimeout returns value of field Timeout
+func (o *DownOptions) GetTimeout() uint {
+	if o.Timeout == nil {
+		var z uint
+		return z
+	}
+	return *o.Timeout
+}
+
+// WithKeepVolumes set field KeepVolumes to given value
+func (o *DownOptions) WithKeepVolumes(value bool) *DownOptions {
+	o.KeepVolumes = &value
+	return o
+}
+
+// GetKeepVolumes returns value of field KeepVolumes
+func (o *DownOptions) GetKeepVolumes() bool {
+	if o.KeepVolumes == nil {
+		var z bool
+		return z
+	}
+	return *o.KeepVolumes
+}
+
+// WithLabelSelector set field LabelSelector to given value
+func (o *DownOptions) WithLabelSelector(value string) *DownOptions {
+	o.LabelSelector = &value
+	return o
+}
+
+// GetLabelSelector returns value of field LabelSelector
+func (o *DownOptions) GetLabelSelector() string {
+	if o.LabelSelector == nil {
+		var z string
+		return z
+	}
+	return *o.LabelSelector
+}
+
+// WithNamespace set field Namespace to given value
+func (o *DownOptions) WithNamespace(value string) *DownOptions {
+	o.Namespace = &value
+	return o
+}
+
+// GetNamespace returns value of field Namespace
+func (o *DownOptions) GetNamespace() string {
+	if o.Namespace == nil {
+		var z string
+		return z
+	}
+	return *o.Namespace
+}
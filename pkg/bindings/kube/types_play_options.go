@@ -2,6 +2,7 @@
 package kube
 
 import (
+	"io"
 	"net"
 	"net/url"
 
@@ -138,6 +139,66 @@ func (o *PlayOptions) GetNoHosts() bool {
 	return *o.NoHosts
 }
 
+// WithAddHost set field AddHost to given value
+func (o *PlayOptions) WithAddHost(value []string) *PlayOptions {
+	o.AddHost = &value
+	return o
+}
+
+// GetAddHost returns value of field AddHost
+func (o *PlayOptions) GetAddHost() []string {
+	if o.AddHost == nil {
+		var z []string
+		return z
+	}
+	return *o.AddHost
+}
+
+// WithRestart set field Restart to given value
+func (o *PlayOptions) WithRestart(value bool) *PlayOptions {
+	o.Restart = &value
+	return o
+}
+
+// GetRestart returns value of field Restart
+func (o *PlayOptions) GetRestart() bool {
+	if o.Restart == nil {
+		var z bool
+		return z
+	}
+	return *o.Restart
+}
+
+// WithLogLevel set field LogLevel to given value
+func (o *PlayOptions) WithLogLevel(value string) *PlayOptions {
+	o.LogLevel = &value
+	return o
+}
+
+// GetLogLevel returns value of field LogLevel
+func (o *PlayOptions) GetLogLevel() string {
+	if o.LogLevel == nil {
+		var z string
+		return z
+	}
+	return *o.LogLevel
+}
+
+// WithHostname set field Hostname to given value
+func (o *PlayOptions) WithHostname(value string) *PlayOptions {
+	o.Hostname = &value
+	return o
+}
+
+// GetHostname returns value of field Hostname
+func (o *PlayOptions) GetHostname() string {
+	if o.Hostname == nil {
+		var z string
+		return z
+	}
+	return *o.Hostname
+}
+
 // WithQuiet set field Quiet to given value
 func (o *PlayOptions) WithQuiet(value bool) *PlayOptions {
 	o.Quiet = &value
@@ -243,6 +304,21 @@ func (o *PlayOptions) GetConfigMaps() []string {
 	return *o.ConfigMaps
 }
 
+// WithConfigMapReaders set field ConfigMapReaders to given value
+func (o *PlayOptions) WithConfigMapReaders(value []io.Reader) *PlayOptions {
+	o.ConfigMapReaders = &value
+	return o
+}
+
+// GetConfigMapReaders returns value of field ConfigMapReaders
+func (o *PlayOptions) GetConfigMapReaders() []io.Reader {
+	if o.ConfigMapReaders == nil {
+		var z []io.Reader
+		return z
+	}
+	return *o.ConfigMapReaders
+}
+
 // WithLogDriver set field LogDriver to given value
 func (o *PlayOptions) WithLogDriver(value string) *PlayOptions {
 	o.LogDriver = &value
@@ -422,3 +498,423 @@ func (o *PlayOptions) GetNoPodPrefix() bool {
 	}
 	return *o.NoPodPrefix
 }
+
+// WithDryRun set field DryRun to given value
+func (o *PlayOptions) WithDryRun(value bool) *PlayOptions {
+	o.DryRun = &value
+	return o
+}
+
+// GetDryRun returns value of field DryRun
+func (o *PlayOptions) GetDryRun() bool {
+	if o.DryRun == nil {
+		var z bool
+		return z
+	}
+	return *o.DryRun
+}
+
+// WithNamespace set field Namespace to given value
+func (o *PlayOptions) WithNamespace(value string) *PlayOptions {
+	o.Namespace = &value
+	return o
+}
+
+// GetNamespace returns value of field Namespace
+func (o *PlayOptions) GetNamespace() string {
+	if o.Namespace == nil {
+		var z string
+		return z
+	}
+	return *o.Namespace
+}
+
+// WithPullPolicy set field PullPolicy to given value
+func (o *PlayOptions) WithPullPolicy(value string) *PlayOptions {
+	o.PullPolicy = &value
+	return o
+}
+
+// GetPullPolicy returns value of field PullPolicy
+func (o *PlayOptions) GetPullPolicy() string {
+	if o.PullPolicy == nil {
+		var z string
+		return z
+	}
+	return *o.PullPolicy
+}
+
+// WithCPULimit set field CPULimit to given value
+func (o *PlayOptions) WithCPULimit(value string) *PlayOptions {
+	o.CPULimit = &value
+	return o
+}
+
+// GetCPULimit returns value of field CPULimit
+func (o *PlayOptions) GetCPULimit() string {
+	if o.CPULimit == nil {
+		var z string
+		return z
+	}
+	return *o.CPULimit
+}
+
+// WithMemoryLimit set field MemoryLimit to given value
+func (o *PlayOptions) WithMemoryLimit(value string) *PlayOptions {
+	o.MemoryLimit = &value
+	return o
+}
+
+// GetMemoryLimit returns value of field MemoryLimit
+func (o *PlayOptions) GetMemoryLimit() string {
+	if o.MemoryLimit == nil {
+		var z string
+		return z
+	}
+	return *o.MemoryLimit
+}
+
+// WithRetry set field Retry to given value
+func (o *PlayOptions) WithRetry(value uint) *PlayOptions {
+	o.Retry = &value
+	return o
+}
+
+// GetRetry returns value of field Retry
+func (o *PlayOptions) GetRetry() uint {
+	if o.Retry == nil {
+		var z uint
+		return z
+	}
+	return *o.Retry
+}
+
+// WithRetryDelay set field RetryDelay to given value
+func (o *PlayOptions) WithRetryDelay(value string) *PlayOptions {
+	o.RetryDelay = &value
+	return o
+}
+
+// GetRetryDelay returns value of field RetryDelay
+func (o *PlayOptions) GetRetryDelay() string {
+	if o.RetryDelay == nil {
+		var z string
+		return z
+	}
+	return *o.RetryDelay
+}
+
+// WithNamePrefix set field NamePrefix to given value
+func (o *PlayOptions) WithNamePrefix(value string) *PlayOptions {
+	o.NamePrefix = &value
+	return o
+}
+
+// GetNamePrefix returns value of field NamePrefix
+func (o *PlayOptions) GetNamePrefix() string {
+	if o.NamePrefix == nil {
+		var z string
+		return z
+	}
+	return *o.NamePrefix
+}
+
+// WithBuildArgs set field BuildArgs to given value
+func (o *PlayOptions) WithBuildArgs(value map[string]string) *PlayOptions {
+	o.BuildArgs = value
+	return o
+}
+
+// GetBuildArgs returns value of field BuildArgs
+func (o *PlayOptions) GetBuildArgs() map[string]string {
+	if o.BuildArgs == nil {
+		var z map[string]string
+		return z
+	}
+	return o.BuildArgs
+}
+
+// WithNoCache set field NoCache to given value
+func (o *PlayOptions) WithNoCache(value bool) *PlayOptions {
+	o.NoCache = &value
+	return o
+}
+
+// GetNoCache returns value of field NoCache
+func (o *PlayOptions) GetNoCache() bool {
+	if o.NoCache == nil {
+		var z bool
+		return z
+	}
+	return *o.NoCache
+}
+
+// WithBuildParallelism set field BuildParallelism to given value
+func (o *PlayOptions) WithBuildParallelism(value uint) *PlayOptions {
+	o.BuildParallelism = &value
+	return o
+}
+
+// GetBuildParallelism returns value of field BuildParallelism
+func (o *PlayOptions) GetBuildParallelism() uint {
+	if o.BuildParallelism == nil {
+		var z uint
+		return z
+	}
+	return *o.BuildParallelism
+}
+
+// WithTimeout set field Timeout to given value
+func (o *PlayOptions) WithTimeout(value uint) *PlayOptions {
+	o.Timeout = &value
+	return o
+}
+
+// GetTimeout returns value of field Timeout
+func (o *PlayOptions) GetTimeout() uint {
+	if o.Timeout == nil {
+		var z uint
+		return z
+	}
+	return *o.Timeout
+}
+
+// WithAtomic set field Atomic to given value
+func (o *PlayOptions) WithAtomic(value bool) *PlayOptions {
+	o.Atomic = &value
+	return o
+}
+
+// GetAtomic returns value of field Atomic
+func (o *PlayOptions) GetAtomic() bool {
+	if o.Atomic == nil {
+		var z bool
+		return z
+	}
+	return *o.Atomic
+}
+
+// WithSkipExisting set field SkipExisting to given value
+func (o *PlayOptions) WithSkipExisting(value bool) *PlayOptions {
+	o.SkipExisting = &value
+	return o
+}
+
+// GetSkipExisting returns value of field SkipExisting
+func (o *PlayOptions) GetSkipExisting() bool {
+	if o.SkipExisting == nil {
+		var z bool
+		return z
+	}
+	return *o.SkipExisting
+}
+
+// WithAllowPrivileged set field AllowPrivileged to given value
+func (o *PlayOptions) WithAllowPrivileged(value bool) *PlayOptions {
+	o.AllowPrivileged = &value
+	return o
+}
+
+// GetAllowPrivileged returns value of field AllowPrivileged
+func (o *PlayOptions) GetAllowPrivileged() bool {
+	if o.AllowPrivileged == nil {
+		var z bool
+		return z
+	}
+	return *o.AllowPrivileged
+}
+
+// WithRequireDigest set field RequireDigest to given value
+func (o *PlayOptions) WithRequireDigest(value bool) *PlayOptions {
+	o.RequireDigest = &value
+	return o
+}
+
+// GetRequireDigest returns value of field RequireDigest
+func (o *PlayOptions) GetRequireDigest() bool {
+	if o.RequireDigest == nil {
+		var z bool
+		return z
+	}
+	return *o.RequireDigest
+}
+
+// WithAllowedDigests set field AllowedDigests to given value
+func (o *PlayOptions) WithAllowedDigests(value map[string]string) *PlayOptions {
+	o.AllowedDigests = value
+	return o
+}
+
+// GetAllowedDigests returns value of field AllowedDigests
+func (o *PlayOptions) GetAllowedDigests() map[string]string {
+	if o.AllowedDigests == nil {
+		var z map[string]string
+		return z
+	}
+	return o.AllowedDigests
+}
+
+// WithWaitReady set field WaitReady to given value
+func (o *PlayOptions) WithWaitReady(value bool) *PlayOptions {
+	o.WaitReady = &value
+	return o
+}
+
+// GetWaitReady returns value of field WaitReady
+func (o *PlayOptions) GetWaitReady() bool {
+	if o.WaitReady == nil {
+		var z bool
+		return z
+	}
+	return *o.WaitReady
+}
+
+// WithEnvFile set field EnvFile to given value
+func (o *PlayOptions) WithEnvFile(value string) *PlayOptions {
+	o.EnvFile = &value
+	return o
+}
+
+// GetEnvFile returns value of field EnvFile
+func (o *PlayOptions) GetEnvFile() string {
+	if o.EnvFile == nil {
+		var z string
+		return z
+	}
+	return *o.EnvFile
+}
+
+// WithCgroupParent set field CgroupParent to given value
+func (o *PlayOptions) WithCgroupParent(value string) *PlayOptions {
+	o.CgroupParent = &value
+	return o
+}
+
+// GetCgroupParent returns value of field CgroupParent
+func (o *PlayOptions) GetCgroupParent() string {
+	if o.CgroupParent == nil {
+		var z string
+		return z
+	}
+	return *o.CgroupParent
+}
+
+// WithSysctl set field Sysctl to given value
+func (o *PlayOptions) WithSysctl(value []string) *PlayOptions {
+	o.Sysctl = value
+	return o
+}
+
+// GetSysctl returns value of field Sysctl
+func (o *PlayOptions) GetSysctl() []string {
+	if o.Sysctl == nil {
+		var z []string
+		return z
+	}
+	return o.Sysctl
+}
+
+// WithDNSServer set field DNSServer to given value
+func (o *PlayOptions) WithDNSServer(value []string) *PlayOptions {
+	o.DNSServer = value
+	return o
+}
+
+// GetDNSServer returns value of field DNSServer
+func (o *PlayOptions) GetDNSServer() []string {
+	if o.DNSServer == nil {
+		var z []string
+		return z
+	}
+	return o.DNSServer
+}
+
+// WithDNSSearch set field DNSSearch to given value
+func (o *PlayOptions) WithDNSSearch(value []string) *PlayOptions {
+	o.DNSSearch = value
+	return o
+}
+
+// GetDNSSearch returns value of field DNSSearch
+func (o *PlayOptions) GetDNSSearch() []string {
+	if o.DNSSearch == nil {
+		var z []string
+		return z
+	}
+	return o.DNSSearch
+}
+
+// WithDNSOption set field DNSOption to given value
+func (o *PlayOptions) WithDNSOption(value []string) *PlayOptions {
+	o.DNSOption = value
+	return o
+}
+
+// GetDNSOption returns value of field DNSOption
+func (o *PlayOptions) GetDNSOption() []string {
+	if o.DNSOption == nil {
+		var z []string
+		return z
+	}
+	return o.DNSOption
+}
+
+// WithValidate set field Validate to given value
+func (o *PlayOptions) WithValidate(value string) *PlayOptions {
+	o.Validate = &value
+	return o
+}
+
+// GetValidate returns value of field Validate
+func (o *PlayOptions) GetValidate() string {
+	if o.Validate == nil {
+		var z string
+		return z
+	}
+	return *o.Validate
+}
+
+// WithMirror set field Mirror to given value
+func (o *PlayOptions) WithMirror(value string) *PlayOptions {
+	o.Mirror = &value
+	return o
+}
+
+// GetMirror returns value of field Mirror
+func (o *PlayOptions) GetMirror() string {
+	if o.Mirror == nil {
+		var z string
+		return z
+	}
+	return *o.Mirror
+}
+
+// WithUpload set field Upload to given value
+func (o *PlayOptions) WithUpload(value string) *PlayOptions {
+	o.Upload = &value
+	return o
+}
+
+// GetUpload returns value of field Upload
+func (o *PlayOptions) GetUpload() string {
+	if o.Upload == nil {
+		var z string
+		return z
+	}
+	return *o.Upload
+}
+
+// WithUploadChunkSize set field UploadChunkSize to given value
+func (o *PlayOptions) WithUploadChunkSize(value int64) *PlayOptions {
+	o.UploadChunkSize = &value
+	return o
+}
+
+// GetUploadChunkSize returns value of field UploadChunkSize
+func (o *PlayOptions) GetUploadChunkSize() int64 {
+	if o.UploadChunkSize == nil {
+		var z int64
+		return z
+	}
+	return *o.UploadChunkSize
+}
@@ -0,0 +1,149 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/containers/podman/v5/pkg/bindings"
+	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
+	"github.com/sirupsen/logrus"
+)
+
+// uploadChunkRetries is the number of times a chunk is resumed after the
+// request sending it failed.
+const uploadChunkRetries = 3
+
+// uploadPlayTar uploads the tar stream r in chunks the size of buf and returns
+// the ID of the upload. The upload is abandoned when a chunk cannot be sent.
+func uploadPlayTar(ctx context.Context, r io.Reader, buf []byte) (string, error) {
+	conn, err := bindings.GetClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	response, err := conn.DoRequest(ctx, nil, http.MethodPost, "/kube/play/uploads", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	var report entitiesTypes.KubePlayUploadReport
+	if err := response.Process(&report); err != nil {
+		return "", err
+	}
+
+	if err := sendPlayTar(ctx, conn, report.ID, r, buf); err != nil {
+		if err := deleteUpload(ctx, conn, report.ID); err != nil {
+			logrus.Debugf("Abandoning kube play upload %s: %v", report.ID, err)
+		}
+		return "", err
+	}
+	return report.ID, nil
+}
+
+// sendPlayTar reads r into buf and sends it to the upload id chunk by chunk.
+func sendPlayTar(ctx context.Context, conn *bindings.Connection, id string, r io.Reader, buf []byte) error {
+	var offset int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if err := sendChunk(ctx, conn, id, buf[:n], offset); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar stream: %w", err)
+		}
+	}
+}
+
+// sendChunk sends chunk, starting at offset in the tar, to the upload id. A
+// failed request is retried from the size the service received so far.
+func sendChunk(ctx context.Context, conn *bindings.Connection, id string, chunk []byte, offset int64) error {
+	end := offset + int64(len(chunk))
+	received := offset
+	var lastErr error
+	for attempt := 0; attempt <= uploadChunkRetries; attempt++ {
+		if attempt > 0 {
+			logrus.Debugf("Resuming chunk %d-%d of kube play upload %s: %v", received, end-1, id, lastErr)
+		}
+		size, err := putChunk(ctx, conn, id, chunk[received-offset:], received)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			// the service may have received part of the chunk
+			if size, err = inspectUpload(ctx, conn, id); err != nil {
+				continue
+			}
+		}
+		if size < offset || size > end {
+			return fmt.Errorf("kube play upload %s: service received %d bytes, expected between %d and %d", id, size, offset, end)
+		}
+		if size == end {
+			return nil
+		}
+		received = size
+		if lastErr == nil {
+			lastErr = fmt.Errorf("chunk cut short at %d bytes", size)
+		}
+	}
+	return fmt.Errorf("uploading chunk %d-%d of kube play upload %s: %w", offset, end-1, id, lastErr)
+}
+
+// putChunk sends data, starting at offset in the tar, to the upload id and
+// returns the size the service received. A chunk refused because it does not
+// start where the upload ends still reports that size.
+func putChunk(ctx context.Context, conn *bindings.Connection, id string, data []byte, offset int64) (int64, error) {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/*", offset, offset+int64(len(data))-1))
+	response, err := conn.DoRequest(ctx, bytes.NewReader(data), http.MethodPut, "/kube/play/uploads/%s", nil, header, id)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	var report entitiesTypes.KubePlayUploadReport
+	if response.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+			return 0, err
+		}
+		return report.Size, nil
+	}
+	if err := response.Process(&report); err != nil {
+		return 0, err
+	}
+	return report.Size, nil
+}
+
+// inspectUpload returns the size the service received for the upload id.
+func inspectUpload(ctx context.Context, conn *bindings.Connection, id string) (int64, error) {
+	response, err := conn.DoRequest(ctx, nil, http.MethodGet, "/kube/play/uploads/%s", nil, nil, id)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	var report entitiesTypes.KubePlayUploadReport
+	if err := response.Process(&report); err != nil {
+		return 0, err
+	}
+	return report.Size, nil
+}
+
+// deleteUpload abandons the upload id.
+func deleteUpload(ctx context.Context, conn *bindings.Connection, id string) error {
+	response, err := conn.DoRequest(ctx, nil, http.MethodDelete, "/kube/play/uploads/%s", nil, nil, id)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return response.Process(nil)
+}
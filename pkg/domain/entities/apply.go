@@ -1,5 +1,9 @@
 package entities
 
+import (
+	"github.com/containers/podman/v5/pkg/domain/entities/types"
+)
+
 var (
 	TypePVC     = "PersistentVolumeClaim"
 	TypePod     = "Pod"
@@ -18,4 +22,16 @@ type ApplyOptions struct {
 	File string
 	// Service - creates a service for the container being deployed.
 	Service bool
+	// ServerSideApply - use Kubernetes server-side apply instead of
+	// creating the objects.
+	ServerSideApply bool
+	// FieldManager - name of the manager owning the applied fields when
+	// using server-side apply. Defaults to "podman".
+	FieldManager string
 }
+
+// ApplyReport lists the objects deployed to a Kubernetes cluster.
+type ApplyReport = types.KubeApplyReport
+
+// ApplyResource describes a single object deployed to a Kubernetes cluster.
+type ApplyResource = types.KubeApplyResource
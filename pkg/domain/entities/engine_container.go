@@ -62,7 +62,7 @@ type ContainerEngine interface { //nolint:interfacebloat
 	SystemPrune(ctx context.Context, options SystemPruneOptions) (*SystemPruneReport, error)
 	HealthCheckRun(ctx context.Context, nameOrID string, options HealthCheckOptions) (*define.HealthCheckResults, error)
 	Info(ctx context.Context) (*define.Info, error)
-	KubeApply(ctx context.Context, body io.Reader, opts ApplyOptions) error
+	KubeApply(ctx context.Context, body io.Reader, opts ApplyOptions) (*ApplyReport, error)
 	Locks(ctx context.Context) (*LocksReport, error)
 	Migrate(ctx context.Context, options SystemMigrateOptions) error
 	NetworkConnect(ctx context.Context, networkname string, options NetworkConnectOptions) error
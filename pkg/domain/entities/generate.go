@@ -29,6 +29,7 @@ type GenerateSystemdReport = types.GenerateSystemdReport
 // GenerateKubeOptions control the generation of Kubernetes YAML files.
 type GenerateKubeOptions struct {
 	// PodmanOnly - add podman-only reserved annotations in the generated YAML file (Cannot be used by Kubernetes)
+	// Healthchecks are then not converted to liveness probes.
 	PodmanOnly bool
 	// Service - generate YAML for a Kubernetes _service_ object.
 	Service bool
@@ -38,6 +39,21 @@ type GenerateKubeOptions struct {
 	Replicas int32
 	// UseLongAnnotations - don't truncate annotations to the Kubernetes maximum length of 63 characters
 	UseLongAnnotations bool
+	// NetworkPolicy - generate a NetworkPolicy for each network the pods are attached to
+	NetworkPolicy bool
+	// Format - output format, "yaml" (default) or "jsonl" for one JSON object per line
+	Format string
+	// Split - generate a tar archive with a YAML file per kube kind instead
+	// of a single YAML file
+	Split bool
+	// PVC - generate a PersistentVolumeClaim for each named volume of the
+	// pods and containers
+	PVC bool
+	// PVCStorageClass - storage class of the generated PersistentVolumeClaims
+	PVCStorageClass string
+	// PVCSize - storage requested by the generated PersistentVolumeClaims,
+	// as a Kubernetes quantity, 1Gi when empty
+	PVCSize string
 }
 
 type KubeGenerateOptions = GenerateKubeOptions
@@ -1,9 +1,11 @@
 package entities
 
 import (
+	"io"
 	"net"
 
 	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
+	"github.com/sirupsen/logrus"
 	"go.podman.io/image/v5/types"
 )
 
@@ -33,6 +35,16 @@ type PlayKubeOptions struct {
 	// Do not create /etc/hosts within the pod's containers,
 	// instead use the version from the image
 	NoHosts bool
+	// AddHost - entries added to the /etc/hosts file of the pods, in the
+	// host:ip format of --add-host
+	AddHost []string
+	// Restart - restart the containers of the pods already played with the
+	// same spec and options instead of failing, and recreate the ones whose
+	// spec or options changed, or which were not played with Restart
+	Restart bool
+	// Hostname - hostname of the pod, overriding the one of the YAML. The
+	// YAML must describe a single pod.
+	Hostname string
 	// Username for authenticating against the registry.
 	Username string
 	// Password for authenticating against the registry.
@@ -83,11 +95,96 @@ type PlayKubeOptions struct {
 	SystemContext *types.SystemContext
 	// Do not prefix container name with pod name
 	NoPodPrefix bool
+	// Writer is used to report image pull and build progress. It takes
+	// precedence over Quiet when set.
+	Writer io.Writer
+	// DryRun - validate the YAML and report the resources that would be
+	// created without creating them
+	DryRun bool
+	// Namespace - prefix the pod names with the namespace and label the
+	// pods with it, so pods of a YAML played in different namespaces do not
+	// collide. Volumes and secrets are not namespaced and are shared.
+	Namespace string
+	// PullPolicy - pull policy applied to all images, overriding the
+	// imagePullPolicy of the containers when set
+	PullPolicy string
+	// CPULimit - maximum cpu limit of every container, as a Kubernetes
+	// quantity. Lower limits set in the YAML win.
+	CPULimit string
+	// MemoryLimit - maximum memory limit of every container, as a Kubernetes
+	// quantity. Lower limits set in the YAML win.
+	MemoryLimit string
+	// Retry - number of times to retry pulling an image in case of failure
+	Retry *uint
+	// RetryDelay - delay between pull retries, exponential backoff when unset
+	RetryDelay string
+	// NamePrefix - prepended to the names of the pods, containers and
+	// volumes created
+	NamePrefix string
+	// BuildArgs - build arguments passed to every image built from the
+	// context directory
+	BuildArgs map[string]string
+	// NoCache - do not use cached layers when building the images from the
+	// context directory
+	NoCache bool
+	// BuildParallelism - maximum number of images built at the same time
+	// from the context directory, they are built when needed by a container
+	// when not greater than 1
+	BuildParallelism uint
+	// Timeout - seconds after which the service gives up on a remote kube
+	// play request, zero means no timeout
+	Timeout uint
+	// Atomic - remove the pods, volumes and secrets created by the request
+	// when it fails. Pods removed by Replace are not restored.
+	Atomic bool
+	// SkipExisting - use the images present in the local storage without
+	// contacting a registry, whatever the pull policy. Missing images are
+	// still pulled.
+	SkipExisting bool
+	// DenyPrivileged - refuse the YAML when one of its containers asks for
+	// privileges
+	DenyPrivileged bool
+	// RequireDigest - refuse the YAML when one of its images is not
+	// referenced by digest
+	RequireDigest bool
+	// AllowedDigests - digest the images, as written in the YAML, must
+	// resolve to
+	AllowedDigests map[string]string
+	// WaitReady - wait for the started containers to be healthy, or running
+	// or exited successfully when they have no healthcheck, before returning
+	WaitReady bool
+	// Env - variables set in each container, unless the YAML defines them
+	Env map[string]string
+	// CgroupParent - parent cgroup of the pods, and so of their containers
+	CgroupParent string
+	// Sysctl - name=value kernel parameters set in every pod, replacing the
+	// values of the YAML
+	Sysctl []string
+	// DNSServers - name servers of the pods, replacing those of the YAML
+	DNSServers []net.IP
+	// DNSSearch - search domains of the pods, replacing those of the YAML
+	DNSSearch []string
+	// DNSOptions - resolver options of the pods, replacing those of the YAML
+	DNSOptions []string
+	// StrictValidation - refuse the documents with unknown fields or values
+	// of the wrong type before playing them
+	StrictValidation bool
+	// RegistryMirror - registry, with an optional repository prefix, the
+	// images of fully-qualified references are pulled from instead of
+	// their own registry
+	RegistryMirror string
+	// Logger - logger for the messages of this play, the standard logger
+	// is used when unset. The messages of the libraries pulling and building
+	// the images are not affected.
+	Logger *logrus.Entry
 }
 
 // PlayKubePod represents a single pod and associated containers created by play kube
 type PlayKubePod = entitiesTypes.PlayKubePod
 
+// PlayKubePodNetwork holds the addresses of a pod in a network.
+type PlayKubePodNetwork = entitiesTypes.PlayKubePodNetwork
+
 // PlayKubeVolume represents a single volume created by play kube.
 type PlayKubeVolume entitiesTypes.PlayKubeVolume
 
@@ -99,9 +196,35 @@ type KubePlayReport = entitiesTypes.KubePlayReport
 type PlayKubeDownOptions struct {
 	// Force - remove volumes if passed
 	Force bool
+	// Timeout - seconds to wait for the containers to stop before killing
+	// them. Zero uses the stop timeout of each container.
+	Timeout uint
+	// KeepVolumes - do not remove the volumes, even with Force
+	KeepVolumes bool
+	// LabelSelector - only tear down the documents whose pods, or the
+	// document itself for other kinds, match this label selector
+	LabelSelector string
+	// Namespace - only tear down the pods played in this namespace, the
+	// volumes and secrets shared by the namespaces are kept
+	Namespace string
 }
 
 // PlayKubeDownReport contains the results of tearing down play kube
 type PlayKubeTeardown = entitiesTypes.PlayKubeTeardown
 
+type PlayKubeDownResource = entitiesTypes.PlayKubeDownResource
+
+// PlayKubeBuild is the outcome of building a single image.
+type PlayKubeBuild = entitiesTypes.PlayKubeBuild
+
+// KubePlayUploadReport describes a kube play context uploaded in chunks.
+type KubePlayUploadReport = entitiesTypes.KubePlayUploadReport
+
+const (
+	PlayKubePodCreated   = entitiesTypes.PlayKubePodCreated
+	PlayKubePodRecreated = entitiesTypes.PlayKubePodRecreated
+	PlayKubePodRestarted = entitiesTypes.PlayKubePodRestarted
+	PlayKubePodUnchanged = entitiesTypes.PlayKubePodUnchanged
+)
+
 type PlaySecret = entitiesTypes.PlaySecret
@@ -0,0 +1,18 @@
+package types
+
+// KubeApplyResource describes an object applied to a Kubernetes cluster.
+type KubeApplyResource struct {
+	// Kind - Kubernetes kind of the object.
+	Kind string
+	// Name - name of the object.
+	Name string
+	// Namespace - namespace the object was applied to.
+	Namespace string
+	// Status - "created" or "updated".
+	Status string
+}
+
+// KubeApplyReport lists the objects applied to a Kubernetes cluster.
+type KubeApplyReport struct {
+	Resources []KubeApplyResource
+}
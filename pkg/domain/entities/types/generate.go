@@ -13,6 +13,9 @@ type GenerateKubeReport struct {
 	// FIXME: Podman4.0 should change io.Reader to io.ReaderCloser
 	// Reader - the io.Reader to reader the generated YAML file.
 	Reader io.Reader
+	// Files - the generated YAML files keyed by name when the output was
+	// split, Reader then holds them as a tar archive.
+	Files map[string][]byte
 }
 
 type GenerateSpecReport struct {
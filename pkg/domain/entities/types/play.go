@@ -3,17 +3,51 @@ package types
 type PlayKubePod struct {
 	// ID - ID of the pod created as a result of play kube.
 	ID string
+	// Name - name of the pod.
+	Name string `json:",omitempty"`
 	// Containers - the IDs of the containers running in the created pod.
 	Containers []string
+	// ContainerNames - the names of the containers of the pod.
+	ContainerNames []string `json:",omitempty"`
 	// InitContainers - the IDs of the init containers to be run in the created pod.
 	InitContainers []string
+	// InitContainerNames - the names of the init containers of the pod.
+	InitContainerNames []string `json:",omitempty"`
 	// Logs - non-fatal errors and log messages while processing.
 	Logs []string
 	// ContainerErrors - any errors that occurred while starting containers
 	// in the pod.
 	ContainerErrors []string
+	// Action - what happened to the pod when playing with Restart, one of
+	// created, recreated, restarted or unchanged.
+	Action string `json:",omitempty"`
+	// Networks - addresses of the pod in each network, once started.
+	Networks []PlayKubePodNetwork `json:",omitempty"`
 }
 
+// PlayKubePodNetwork holds the addresses of a pod in a network.
+type PlayKubePodNetwork struct {
+	// Name - name of the network.
+	Name string
+	// IPs - IPv4 and IPv6 addresses of the pod in the network.
+	IPs []string
+}
+
+// Actions reported for the pods played with Restart.
+const (
+	// PlayKubePodCreated - the pod did not exist and was created.
+	PlayKubePodCreated = "created"
+	// PlayKubePodRecreated - the pod existed with a different spec and was
+	// replaced.
+	PlayKubePodRecreated = "recreated"
+	// PlayKubePodRestarted - the pod existed with the same spec and its
+	// containers were restarted.
+	PlayKubePodRestarted = "restarted"
+	// PlayKubePodUnchanged - the pod existed with the same spec and was left
+	// as is because the pods are not started.
+	PlayKubePodUnchanged = "unchanged"
+)
+
 type PlayKubeVolume struct {
 	// Name - Name of the volume created by play kube.
 	Name string
@@ -29,8 +63,41 @@ type PlayKubeReport struct {
 	Secrets []PlaySecret
 	// ServiceContainerID - ID of the service container if one is created
 	ServiceContainerID string
+	// ServiceContainerName - name of the service container if one is
+	// created, to refer to it from a systemd unit
+	ServiceContainerName string `json:",omitempty"`
 	// If set, exit with the specified exit code.
 	ExitCode *int32
+	// DryRun - the report lists the resources that would be created.
+	// Pods, containers and secrets are then only identified by name, their
+	// IDs are empty, and Pulls and Builds list the images that would be
+	// pulled or built.
+	DryRun bool
+	// Namespace - namespace the pods were created in, if any. Volumes and
+	// secrets are not created in it.
+	Namespace string
+	// Pulls - images pulled from a registry, as opposed to images already
+	// present or built locally.
+	Pulls []string
+	// Warnings - non-fatal problems with the options of the request.
+	Warnings []string
+	// Builds - images built from the context directory for the containers
+	// and image volumes of the pods.
+	Builds []PlayKubeBuild
+	// RolledBack - the request failed and the resources it created were
+	// removed again, see the teardown reports for the outcome.
+	RolledBack bool
+	// EffectiveTLSVerify - whether TLS was verified when connecting to the
+	// registries the images were pulled from.
+	EffectiveTLSVerify bool
+}
+
+// PlayKubeBuild is the outcome of building a single image.
+type PlayKubeBuild struct {
+	// Image - name of the image.
+	Image string
+	// Error - why the image could not be built, empty on success.
+	Error string `json:",omitempty"`
 }
 
 type KubePlayReport = PlayKubeReport
@@ -41,8 +108,36 @@ type PlayKubeTeardown struct {
 	RmReport       []*PodRmReport
 	VolumeRmReport []*VolumeRmReport
 	SecretRmReport []*SecretRmReport
+	// KeptVolumes - volumes left in place because KeepVolumes was set.
+	KeptVolumes []string
+	// Resources - outcome of the removal of each pod, secret and volume.
+	Resources []PlayKubeDownResource
+	// Skipped - kind/name of the documents left in place because they did
+	// not match the label selector.
+	Skipped []string
+}
+
+// PlayKubeDownResource is the outcome of removing a single resource.
+type PlayKubeDownResource struct {
+	// Kind - Pod, Secret or Volume.
+	Kind string
+	// Name - name of the pod or volume, ID of the secret.
+	Name string
+	// Error - why the resource could not be removed, empty on success.
+	Error string `json:",omitempty"`
 }
 
 type PlaySecret struct {
+	// Name - name of the secret.
+	Name string `json:",omitempty"`
+	// CreateReport - the secret created, without an ID on a dry run.
 	CreateReport *SecretCreateReport
 }
+
+// KubePlayUploadReport describes a kube play context uploaded in chunks.
+type KubePlayUploadReport struct {
+	// ID - identifier of the upload, given to the upload play option.
+	ID string
+	// Size - number of bytes received, the offset of the next chunk.
+	Size int64
+}
@@ -3,6 +3,7 @@
 package abi
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -11,34 +12,39 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"strings"
 
+	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	k8sAPI "github.com/containers/podman/v5/pkg/k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 )
 
-func (ic *ContainerEngine) KubeApply(_ context.Context, body io.Reader, options entities.ApplyOptions) error {
+// KubeApply deploys the kube YAML to the cluster of the kubeconfig. When a
+// document fails, the report of the resources deployed before it is returned
+// along with the error.
+func (ic *ContainerEngine) KubeApply(_ context.Context, body io.Reader, options entities.ApplyOptions) (*entities.ApplyReport, error) {
 	// Read the yaml file
 	content, err := io.ReadAll(body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(content) == 0 {
-		return errors.New("yaml file provided is empty, cannot apply to a cluster")
+		return nil, errors.New("yaml file provided is empty, cannot apply to a cluster")
 	}
 
 	// Split the yaml file
 	documentList, err := splitMultiDocYAML(content)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Sort the kube kinds
 	documentList, err = sortKubeKinds(documentList)
 	if err != nil {
-		return fmt.Errorf("unable to sort kube kinds: %w", err)
+		return nil, fmt.Errorf("unable to sort kube kinds: %w", err)
 	}
 
 	// Get the namespace to deploy the workload to
@@ -50,43 +56,53 @@ func (ic *ContainerEngine) KubeApply(_ context.Context, body io.Reader, options
 	// Parse the given kubeconfig
 	kconfig, err := getClusterInfo(options.Kubeconfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Set up the client to connect to the cluster endpoints
 	client, err := setUpClusterClient(kconfig, options)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	report := &entities.ApplyReport{}
 	for _, document := range documentList {
 		kind, err := getKubeKind(document)
 		if err != nil {
-			return fmt.Errorf("unable to read kube YAML: %w", err)
+			return report, fmt.Errorf("unable to read kube YAML: %w", err)
 		}
 
+		var resource string
 		switch kind {
 		case entities.TypeService:
-			url := kconfig.Clusters[0].Cluster.Server + "/api/v1/namespaces/" + namespace + "/services"
-			if err := createObject(client, url, document); err != nil {
-				return err
-			}
+			resource = "services"
 		case entities.TypePVC:
-			url := kconfig.Clusters[0].Cluster.Server + "/api/v1/namespaces/" + namespace + "/persistentvolumeclaims"
-			if err := createObject(client, url, document); err != nil {
-				return err
-			}
+			resource = "persistentvolumeclaims"
 		case entities.TypePod:
-			url := kconfig.Clusters[0].Cluster.Server + "/api/v1/namespaces/" + namespace + "/pods"
-			if err := createObject(client, url, document); err != nil {
-				return err
-			}
+			resource = "pods"
 		default:
-			return fmt.Errorf("unsupported Kubernetes kind found: %q", kind)
+			return report, fmt.Errorf("unsupported Kubernetes kind found: %q", kind)
 		}
+
+		name, err := getObjectName(document)
+		if err != nil {
+			return report, fmt.Errorf("unable to read kube YAML: %w", err)
+		}
+
+		url := kconfig.Clusters[0].Cluster.Server + "/api/v1/namespaces/" + namespace + "/" + resource
+		var status string
+		if options.ServerSideApply {
+			status, err = applyObject(client, url, name, document, options.FieldManager)
+		} else {
+			status, err = createObject(client, url, document)
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Resources = append(report.Resources, entities.ApplyResource{Kind: kind, Name: name, Namespace: namespace, Status: status})
 	}
 
-	return nil
+	return report, nil
 }
 
 // setUpClusterClient sets up the client to use when connecting to the cluster. It sets up the CA Certs and
@@ -147,17 +163,18 @@ func setUpClusterClient(kconfig k8sAPI.Config, applyOptions entities.ApplyOption
 	return &http.Client{Transport: tr}, nil
 }
 
-// createObject connects to the given url and creates the yaml given in objectData
-func createObject(client *http.Client, url string, objectData []byte) error {
+// createObject connects to the given url and creates the yaml given in objectData.
+// It returns the status of the object, "created".
+func createObject(client *http.Client, url string, objectData []byte) (string, error) {
 	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(objectData)))
 	if err != nil {
-		return err
+		return "", err
 	}
 	req.Header.Set("Content-Type", "application/yaml")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -165,11 +182,66 @@ func createObject(client *http.Client, url string, objectData []byte) error {
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return err
+			return "", err
 		}
-		return errors.New(string(body))
+		return "", errors.New(string(body))
+	}
+	return "created", nil
+}
+
+// getObjectName returns the name from the metadata of the kube object.
+func getObjectName(objectData []byte) (string, error) {
+	var object struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(objectData, &object); err != nil {
+		return "", err
+	}
+	return object.Metadata.Name, nil
+}
+
+// applyObject server-side applies the yaml given in objectData as the object
+// name of the collection at url. It returns the status of the object,
+// "created" or "updated". Conflicts with fields owned by another manager are
+// reported as define.ErrKubeApplyConflict along with the cluster's details.
+func applyObject(client *http.Client, url, name string, objectData []byte, fieldManager string) (string, error) {
+	if name == "" {
+		return "", errors.New("server-side apply requires the object to have a name")
+	}
+	if fieldManager == "" {
+		fieldManager = "podman"
+	}
+
+	query := neturl.Values{}
+	query.Set("fieldManager", fieldManager)
+	req, err := http.NewRequest(http.MethodPatch, url+"/"+neturl.PathEscape(name)+"?"+query.Encode(), bytes.NewReader(objectData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/apply-patch+yaml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return "created", nil
+	case http.StatusOK:
+		return "updated", nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return "", fmt.Errorf("%w: %s", define.ErrKubeApplyConflict, body)
 	}
-	return nil
+	return "", errors.New(string(body))
 }
 
 // getClusterInfo returns the kubeconfig in struct form so that the server
@@ -0,0 +1,91 @@
+//go:build !remote
+
+package abi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyObject(t *testing.T) {
+	const pod = "apiVersion: v1\nkind: Pod\nmetadata:\n  name: foo\n"
+
+	var (
+		status int
+		req    *http.Request
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"reason":"Conflict"}`))
+	}))
+	defer server.Close()
+	url := server.URL + "/api/v1/namespaces/default/pods"
+
+	status = http.StatusOK
+	applied, err := applyObject(server.Client(), url, "foo", []byte(pod), "")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", applied)
+	assert.Equal(t, http.MethodPatch, req.Method)
+	assert.Equal(t, "/api/v1/namespaces/default/pods/foo", req.URL.Path)
+	assert.Equal(t, "podman", req.URL.Query().Get("fieldManager"))
+	assert.Equal(t, "application/apply-patch+yaml", req.Header.Get("Content-Type"))
+	assert.Equal(t, pod, string(body))
+
+	status = http.StatusCreated
+	applied, err = applyObject(server.Client(), url, "foo", []byte(pod), "")
+	require.NoError(t, err)
+	assert.Equal(t, "created", applied)
+
+	status = http.StatusConflict
+	_, err = applyObject(server.Client(), url, "foo", []byte(pod), "ci")
+	assert.ErrorIs(t, err, define.ErrKubeApplyConflict)
+	assert.ErrorContains(t, err, `{"reason":"Conflict"}`)
+	assert.Equal(t, "ci", req.URL.Query().Get("fieldManager"))
+
+	status = http.StatusInternalServerError
+	_, err = applyObject(server.Client(), url, "foo", []byte(pod), "")
+	assert.NotErrorIs(t, err, define.ErrKubeApplyConflict)
+	assert.EqualError(t, err, `{"reason":"Conflict"}`)
+
+	_, err = applyObject(server.Client(), url, "", []byte("kind: Pod\n"), "")
+	assert.EqualError(t, err, "server-side apply requires the object to have a name")
+}
+
+func TestKubeApplyPartialReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "name: bar") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("cannot create bar"))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	config := fmt.Sprintf("apiVersion: v1\nkind: Config\nclusters:\n- name: test\n  cluster:\n    server: %s\nusers:\n- name: test\n  user: {}\n", server.URL)
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(config), 0o600))
+
+	yaml := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: foo\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: bar\n"
+	ic := &ContainerEngine{}
+	report, err := ic.KubeApply(context.Background(), strings.NewReader(yaml), entities.ApplyOptions{Kubeconfig: kubeconfig})
+	assert.EqualError(t, err, "cannot create bar")
+	require.NotNil(t, report)
+	assert.Equal(t, []entities.ApplyResource{{Kind: "Pod", Name: "foo", Namespace: "default", Status: "created"}}, report.Resources)
+}
@@ -3,16 +3,22 @@
 package abi
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/containers/podman/v5/libpod"
 	"github.com/containers/podman/v5/libpod/define"
 	"github.com/containers/podman/v5/pkg/domain/entities"
 	k8sAPI "github.com/containers/podman/v5/pkg/k8s.io/api/core/v1"
+	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/api/resource"
 	"github.com/containers/podman/v5/pkg/specgen"
 	generateUtils "github.com/containers/podman/v5/pkg/specgen/generate"
 	"github.com/containers/podman/v5/pkg/systemd/generate"
@@ -189,9 +195,17 @@ func (ic *ContainerEngine) GenerateKube(ctx context.Context, nameOrIDs []string,
 		content = append(content, []byte(warning))
 	}
 
+	if options.PVC {
+		var err error
+		vols, err = ic.appendNamedVolumes(vols, pods, ctrs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate kube persistent volume claims from volumes.
 	if len(vols) >= 1 {
-		pvs, err := getKubePVCs(vols)
+		pvs, err := getKubePVCs(vols, options)
 		if err != nil {
 			return nil, err
 		}
@@ -285,8 +299,37 @@ func (ic *ContainerEngine) GenerateKube(ctx context.Context, nameOrIDs []string,
 	// Content order is based on helm install order (secret, persistentVolumeClaim, service, pod/deployment).
 	content = append(content, typeContent...)
 
+	if options.NetworkPolicy {
+		policies, err := getKubeNetworkPolicies(pods, ctrs)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, policies...)
+	}
+
+	// Sort the kube kinds so the same objects always produce the same output.
+	content, err := sortKubeOutput(content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate kube YAML file from all kube kinds.
-	k, err := generateKubeOutput(content)
+	var k []byte
+	switch options.Format {
+	case "", "yaml":
+		if options.Split {
+			k, err = generateKubeSplit(content)
+			break
+		}
+		k, err = generateKubeOutput(content)
+	case "jsonl":
+		if options.Split {
+			return nil, errors.New("split output is only supported with the yaml format")
+		}
+		k, err = generateKubeJSONLines(content)
+	default:
+		return nil, fmt.Errorf("invalid format %q: must be yaml or jsonl", options.Format)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -362,12 +405,84 @@ func getKubePods(ctx context.Context, pods []*libpod.Pod, options entities.Gener
 	return out, svcs, nil
 }
 
-// getKubePVCs returns kube persistent volume claim YAML files from podman volumes.
-func getKubePVCs(volumes []*libpod.Volume) ([][]byte, error) {
+// getKubeNetworkPolicies returns a kube network policy YAML file for each
+// network the pods, or the pod generated from the containers, are attached to.
+func getKubeNetworkPolicies(pods []*libpod.Pod, ctrs []*libpod.Container) ([][]byte, error) {
+	networkApps := make(map[string][]string)
+	addApp := func(app string, ctrs ...*libpod.Container) error {
+		for _, ctr := range ctrs {
+			networks, err := ctr.Networks()
+			if err != nil {
+				return err
+			}
+			for _, network := range networks {
+				if !slices.Contains(networkApps[network], app) {
+					networkApps[network] = append(networkApps[network], app)
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, p := range pods {
+		// The app label of the generated pod.
+		app := strings.ReplaceAll(p.Name(), "_", "")
+		podCtrs, err := p.AllContainers()
+		if err != nil {
+			return nil, err
+		}
+		if p.HasInfraContainer() {
+			infra, err := p.InfraContainer()
+			if err != nil {
+				return nil, err
+			}
+			podCtrs = []*libpod.Container{infra}
+		}
+		if err := addApp(app, podCtrs...); err != nil {
+			return nil, err
+		}
+	}
+	if len(ctrs) > 0 {
+		// Containers are generated as a single pod named after the first
+		// one with a -pod suffix.
+		app := strings.ReplaceAll(ctrs[0].Name(), "_", "") + "-pod"
+		if err := addApp(app, ctrs...); err != nil {
+			return nil, err
+		}
+	}
+
+	policies := [][]byte{}
+	for _, network := range slices.Sorted(maps.Keys(networkApps)) {
+		b, err := generateKubeYAML(libpod.GenerateKubeNetworkPolicy(network, networkApps[network]))
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, b)
+	}
+	return policies, nil
+}
+
+// getKubePVCs returns kube persistent volume claim YAML files from podman
+// volumes, with the storage class and size of the options when set.
+func getKubePVCs(volumes []*libpod.Volume, options entities.GenerateKubeOptions) ([][]byte, error) {
 	pvs := [][]byte{}
 
+	var size resource.Quantity
+	if options.PVCSize != "" {
+		var err error
+		if size, err = resource.ParseQuantity(options.PVCSize); err != nil {
+			return nil, fmt.Errorf("invalid PersistentVolumeClaim size %q: %w", options.PVCSize, err)
+		}
+	}
 	for _, v := range volumes {
-		b, err := generateKubeYAML(v.GenerateForKube())
+		pvc := v.GenerateForKube()
+		if options.PVCStorageClass != "" {
+			pvc.Spec.StorageClassName = &options.PVCStorageClass
+		}
+		if options.PVCSize != "" {
+			pvc.Spec.Resources.Requests[k8sAPI.ResourceStorage] = size
+		}
+		b, err := generateKubeYAML(pvc)
 		if err != nil {
 			return nil, err
 		}
@@ -377,6 +492,32 @@ func getKubePVCs(volumes []*libpod.Volume) ([][]byte, error) {
 	return pvs, nil
 }
 
+// appendNamedVolumes appends the named volumes of the containers of pods and
+// of ctrs to vols, each volume once.
+func (ic *ContainerEngine) appendNamedVolumes(vols []*libpod.Volume, pods []*libpod.Pod, ctrs []*libpod.Container) ([]*libpod.Volume, error) {
+	allCtrs := slices.Clone(ctrs)
+	for _, p := range pods {
+		podCtrs, err := p.AllContainers()
+		if err != nil {
+			return nil, err
+		}
+		allCtrs = append(allCtrs, podCtrs...)
+	}
+	for _, ctr := range allCtrs {
+		for _, namedVolume := range ctr.NamedVolumes() {
+			if slices.ContainsFunc(vols, func(v *libpod.Volume) bool { return v.Name() == namedVolume.Name }) {
+				continue
+			}
+			vol, err := ic.Libpod.LookupVolume(namedVolume.Name)
+			if err != nil {
+				return nil, err
+			}
+			vols = append(vols, vol)
+		}
+	}
+	return vols, nil
+}
+
 // generateKubeYAML marshalls a kube kind into a YAML file.
 func generateKubeYAML(kubeKind any) ([]byte, error) {
 	b, err := yaml.Marshal(kubeKind)
@@ -387,6 +528,65 @@ func generateKubeYAML(kubeKind any) ([]byte, error) {
 	return b, nil
 }
 
+// kubeOutputKindRank returns the position of kind in the generated kube YAML
+// file, following the helm install order. Documents without a kind, like the
+// warnings, come first.
+func kubeOutputKindRank(kind string) int {
+	switch kind {
+	case "":
+		return 0
+	case "Namespace":
+		return 1
+	case "ConfigMap":
+		return 2
+	case "Secret":
+		return 3
+	case "PersistentVolumeClaim":
+		return 4
+	case "Service":
+		return 5
+	case "Pod", "Deployment", "DaemonSet", "Job":
+		return 6
+	default:
+		return 7
+	}
+}
+
+// sortKubeOutput sorts the kube kinds by kind, see kubeOutputKindRank, and
+// then by name. Documents of the same kind and name keep their order.
+func sortKubeOutput(content [][]byte) ([][]byte, error) {
+	type document struct {
+		rank int
+		name string
+		data []byte
+	}
+	documents := make([]document, 0, len(content))
+	for _, b := range content {
+		var object struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal(b, &object); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document{rank: kubeOutputKindRank(object.Kind), name: object.Metadata.Name, data: b})
+	}
+	slices.SortStableFunc(documents, func(a, b document) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	sorted := make([][]byte, 0, len(documents))
+	for _, d := range documents {
+		sorted = append(sorted, d.data)
+	}
+	return sorted, nil
+}
+
 // generateKubeOutput generates kube YAML file containing multiple kube kinds.
 func generateKubeOutput(content [][]byte) ([]byte, error) {
 	output := make([]byte, 0)
@@ -416,3 +616,64 @@ func generateKubeOutput(content [][]byte) ([]byte, error) {
 
 	return output, nil
 }
+
+// generateKubeSplit generates a tar archive holding a <kind>-<name>.yaml kube
+// YAML file for each kube kind. Documents made only of comments, like the
+// warnings, are dropped.
+func generateKubeSplit(content [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	now := time.Now()
+	for _, b := range content {
+		var object struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal(b, &object); err != nil {
+			return nil, err
+		}
+		if object.Kind == "" {
+			continue
+		}
+		file, err := generateKubeOutput([][]byte{b})
+		if err != nil {
+			return nil, err
+		}
+		hdr := &tar.Header{
+			Name:    fmt.Sprintf("%s-%s.yaml", strings.ToLower(object.Kind), object.Metadata.Name),
+			Mode:    0o644,
+			Size:    int64(len(file)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(file); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generateKubeJSONLines generates one JSON object per line from the kube kinds.
+// Documents made only of comments, like the warnings, are dropped.
+func generateKubeJSONLines(content [][]byte) ([]byte, error) {
+	output := make([]byte, 0)
+	for _, b := range content {
+		j, err := yaml.YAMLToJSON(b)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(j, []byte("null")) {
+			continue
+		}
+		output = append(output, j...)
+		output = append(output, '\n')
+	}
+	return output, nil
+}
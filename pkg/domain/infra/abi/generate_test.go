@@ -0,0 +1,92 @@
+//go:build !remote
+
+package abi
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/containers/podman/v5/pkg/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKubeJSONLines(t *testing.T) {
+	content := [][]byte{
+		[]byte("\n# NOTE: a warning\n"),
+		[]byte("apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: vol\n"),
+		[]byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\n"),
+	}
+	out, err := generateKubeJSONLines(content)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"name":"vol"}}
+{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod"}}
+`, string(out))
+}
+
+func TestSortKubeOutput(t *testing.T) {
+	var (
+		warning = []byte("\n# NOTE: a warning\n")
+		pvc     = []byte("apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: vol\n")
+		svcA    = []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: a\n")
+		svcB    = []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: b\n")
+		podA    = []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: a\n")
+		podB    = []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: b\n")
+		secret  = []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: z\n")
+	)
+
+	generate := func(content ...[]byte) []byte {
+		sorted, err := sortKubeOutput(content)
+		require.NoError(t, err)
+		out, err := generateKubeOutput(sorted)
+		require.NoError(t, err)
+		return out
+	}
+
+	first := generate(podB, svcB, warning, podA, pvc, svcA, secret)
+	second := generate(svcA, podA, secret, warning, pvc, svcB, podB)
+	assert.Equal(t, first, second)
+
+	sorted, err := sortKubeOutput([][]byte{podB, svcB, warning, podA, pvc, svcA, secret})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{warning, secret, pvc, svcA, svcB, podA, podB}, sorted)
+}
+
+func TestGenerateKubeSplit(t *testing.T) {
+	content := [][]byte{
+		[]byte("\n# NOTE: a warning\n"),
+		[]byte("apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: vol\n"),
+		[]byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\n"),
+	}
+	out, err := generateKubeSplit(content)
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	tr := tar.NewReader(bytes.NewReader(out))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(b)
+	}
+	assert.Len(t, files, 2)
+	assert.Contains(t, files["persistentvolumeclaim-vol.yaml"], "kind: PersistentVolumeClaim\n")
+	assert.Contains(t, files["pod-pod.yaml"], "kind: Pod\n")
+	assert.NotContains(t, files["pod-pod.yaml"], "---")
+}
+
+func TestGetKubePVCsInvalidSize(t *testing.T) {
+	_, err := getKubePVCs(nil, entities.GenerateKubeOptions{PVCSize: "lots"})
+	assert.ErrorContains(t, err, `invalid PersistentVolumeClaim size "lots"`)
+
+	pvs, err := getKubePVCs(nil, entities.GenerateKubeOptions{PVCSize: "5Gi"})
+	assert.NoError(t, err)
+	assert.Empty(t, pvs)
+}
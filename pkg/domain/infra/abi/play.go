@@ -5,15 +5,19 @@ package abi
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"maps"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	buildahDefine "github.com/containers/buildah/define"
 	bparse "github.com/containers/buildah/pkg/parse"
@@ -26,6 +30,7 @@ import (
 	"github.com/containers/podman/v5/pkg/domain/infra/abi/internal/expansion"
 	v1apps "github.com/containers/podman/v5/pkg/k8s.io/api/apps/v1"
 	v1 "github.com/containers/podman/v5/pkg/k8s.io/api/core/v1"
+	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/api/resource"
 	metav1 "github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/containers/podman/v5/pkg/specgen"
 	"github.com/containers/podman/v5/pkg/specgen/generate"
@@ -42,9 +47,12 @@ import (
 	"go.podman.io/common/pkg/config"
 	"go.podman.io/common/pkg/secrets"
 	"go.podman.io/image/v5/docker/reference"
+	"go.podman.io/image/v5/pkg/sysregistriesv2"
 	"go.podman.io/image/v5/types"
+	"go.podman.io/storage"
 	"go.podman.io/storage/pkg/archive"
 	"go.podman.io/storage/pkg/fileutils"
+	"golang.org/x/sync/errgroup"
 	yamlv3 "gopkg.in/yaml.v3"
 	"sigs.k8s.io/yaml"
 )
@@ -56,6 +64,217 @@ const sdNotifyAnnotation = "io.containers.sdnotify"
 // default network created/used by kube
 const kubeDefaultNetwork = "podman-default-kube-network"
 
+// kubeNamespaceLabel is set on the pods played with a namespace.
+const kubeNamespaceLabel = "io.podman.kube.namespace"
+
+// ErrPrivilegedNotAllowed is returned when playing privileged containers with
+// DenyPrivileged.
+var ErrPrivilegedNotAllowed = errors.New("privileged containers are not allowed")
+
+// ErrSysctlConflict is returned when a Sysctl option cannot be set because
+// the pod uses a namespace of the host.
+var ErrSysctlConflict = errors.New("sysctl conflicts with the pod namespaces")
+
+// ErrInvalidKubeYAML is returned when a document does not match the schema of
+// its kind with StrictValidation.
+var ErrInvalidKubeYAML = errors.New("invalid kube YAML")
+
+// ErrImageNotAllowed is returned when playing an image that is not pinned to
+// a digest with RequireDigest, or that does not resolve to its allowed digest.
+var ErrImageNotAllowed = errors.New("image not allowed")
+
+// waitReadyInterval is how often the containers are checked with WaitReady.
+const waitReadyInterval = 250 * time.Millisecond
+
+// kubeSpecDigestLabel is set on the pods played with Restart to the digest of
+// their spec, to tell whether a pod needs to be recreated when played again.
+const kubeSpecDigestLabel = "io.podman.kube.spec-digest"
+
+// kubePodDigest returns the digest of the pod spec, annotations and of the
+// play options applied to the pod, the maps are marshalled with sorted keys so
+// identical specs get the same digest.
+func kubePodDigest(podYAML *v1.PodTemplateSpec, annotations map[string]string, options entities.PlayKubeOptions) (string, error) {
+	data, err := json.Marshal(struct {
+		Pod         *v1.PodTemplateSpec
+		Annotations map[string]string
+		Options     any
+	}{podYAML, annotations, struct {
+		AddHost         []string
+		NoHosts         bool
+		NoHostname      bool
+		DNSServers      []net.IP
+		DNSSearch       []string
+		DNSOptions      []string
+		Sysctl          []string
+		Networks        []string
+		StaticIPs       []net.IP
+		StaticMACs      []net.HardwareAddr
+		PublishPorts    []string
+		PublishAllPorts bool
+		Userns          string
+		CgroupParent    string
+		Env             map[string]string
+		CPULimit        string
+		MemoryLimit     string
+		LogDriver       string
+		LogOptions      []string
+	}{
+		options.AddHost, options.NoHosts, options.NoHostname,
+		options.DNSServers, options.DNSSearch, options.DNSOptions, options.Sysctl,
+		options.Networks, options.StaticIPs, options.StaticMACs,
+		options.PublishPorts, options.PublishAllPorts,
+		options.Userns, options.CgroupParent, options.Env,
+		options.CPULimit, options.MemoryLimit, options.LogDriver, options.LogOptions,
+	}})
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(data).Encoded(), nil
+}
+
+// resourceCeilings returns the cpu and memory limits set by the options.
+func resourceCeilings(options entities.PlayKubeOptions) (v1.ResourceList, error) {
+	ceilings := v1.ResourceList{}
+	for name, value := range map[v1.ResourceName]string{v1.ResourceCPU: options.CPULimit, v1.ResourceMemory: options.MemoryLimit} {
+		if value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s limit %q: %w", name, value, err)
+		}
+		ceilings[name] = q
+	}
+	return ceilings, nil
+}
+
+// capResources lowers the limits of the container to the ceilings, setting
+// them when missing. Requests above a ceiling are lowered as well so they
+// never exceed the limit.
+func capResources(container *v1.Container, ceilings v1.ResourceList) {
+	for name, ceiling := range ceilings {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = v1.ResourceList{}
+		}
+		if limit, ok := container.Resources.Limits[name]; !ok || limit.Cmp(ceiling) > 0 {
+			container.Resources.Limits[name] = ceiling.DeepCopy()
+		}
+		if request, ok := container.Resources.Requests[name]; ok && request.Cmp(ceiling) > 0 {
+			container.Resources.Requests[name] = ceiling.DeepCopy()
+		}
+	}
+}
+
+// addEnv adds the variables of env the container does not define, sorted by
+// name so the spec is the same each time the YAML is played.
+func addEnv(container *v1.Container, env map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		if slices.ContainsFunc(container.Env, func(e v1.EnvVar) bool { return e.Name == name }) {
+			continue
+		}
+		container.Env = append(container.Env, v1.EnvVar{Name: name, Value: env[name]})
+	}
+}
+
+// mirrorImage replaces the registry of the fully-qualified reference image
+// with mirror, keeping its repository, tag and digest. Short names, resolved
+// through the registries configuration, local images and invalid references
+// are returned as is.
+func mirrorImage(image, mirror string) string {
+	if mirror == "" {
+		return image
+	}
+	domain, _, found := strings.Cut(image, "/")
+	if !found || !strings.ContainsAny(domain, ".:") || domain == "localhost" || strings.HasPrefix(domain, "localhost:") {
+		return image
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	mirrored := mirror + "/" + reference.Path(named)
+	if tagged, ok := named.(reference.Tagged); ok {
+		mirrored += ":" + tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		mirrored += "@" + digested.Digest().String()
+	}
+	return mirrored
+}
+
+// mergeSysctls adds the name=value entries of sysctls to those of the pod,
+// replacing the values of the pod for the same names.
+func mergeSysctls(podSysctls, sysctls []string, podSpec v1.PodSpec) ([]string, error) {
+	merged := make(map[string]string, len(podSysctls)+len(sysctls))
+	for _, sysctl := range podSysctls {
+		name, value, _ := strings.Cut(sysctl, "=")
+		merged[name] = value
+	}
+	for _, sysctl := range sysctls {
+		name, value, _ := strings.Cut(sysctl, "=")
+		if podSpec.HostNetwork && strings.HasPrefix(name, "net.") {
+			return nil, fmt.Errorf("%w: %s cannot be set on a pod using the host network", ErrSysctlConflict, name)
+		}
+		if podSpec.HostIPC && strings.HasPrefix(name, "fs.mqueue.") {
+			return nil, fmt.Errorf("%w: %s cannot be set on a pod using the host IPC namespace", ErrSysctlConflict, name)
+		}
+		merged[name] = value
+	}
+	result := make([]string, 0, len(merged))
+	for _, name := range slices.Sorted(maps.Keys(merged)) {
+		result = append(result, name+"="+merged[name])
+	}
+	return result, nil
+}
+
+// effectiveTLSVerify reports whether TLS was verified when pulling the images:
+// SkipTLSVerify applies to every registry when set, the insecure setting of
+// the registries in registries.conf otherwise.
+func effectiveTLSVerify(skipTLSVerify types.OptionalBool, pulls []string, sys *types.SystemContext) bool {
+	switch skipTLSVerify {
+	case types.OptionalBoolTrue:
+		return false
+	case types.OptionalBoolFalse:
+		return true
+	}
+	for _, image := range pulls {
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			continue
+		}
+		registry, err := sysregistriesv2.FindRegistry(sys, named.Name())
+		if err == nil && registry != nil && registry.Insecure {
+			return false
+		}
+	}
+	return true
+}
+
+// appendPull adds image to the pulled images unless it is already listed.
+func appendPull(pulls []string, image string) []string {
+	if slices.Contains(pulls, image) {
+		return pulls
+	}
+	return append(pulls, image)
+}
+
+// appendBuild records the successful build of image unless it is already
+// listed.
+func appendBuild(builds []entitiesTypes.PlayKubeBuild, image string) []entitiesTypes.PlayKubeBuild {
+	if slices.Contains(builds, entitiesTypes.PlayKubeBuild{Image: image}) {
+		return builds
+	}
+	return append(builds, entitiesTypes.PlayKubeBuild{Image: image})
+}
+
+// namespacedPodName returns the name of the pod podName played in namespace.
+func namespacedPodName(namespace, podName string) string {
+	if namespace == "" || podName == "" {
+		return podName
+	}
+	return fmt.Sprintf("%s-%s", namespace, podName)
+}
+
 // createServiceContainer creates a container that can later on
 // be associated with the pods of a K8s yaml.  It will be started along with
 // the first pod.
@@ -126,7 +345,7 @@ func (ic *ContainerEngine) createServiceContainer(ctx context.Context, name stri
 	return ctr, nil
 }
 
-func (ic *ContainerEngine) prepareAutomountImages(ctx context.Context, forContainer string, annotations map[string]string) ([]*specgen.ImageVolume, error) {
+func (ic *ContainerEngine) prepareAutomountImages(ctx context.Context, logger *logrus.Entry, forContainer string, annotations map[string]string) ([]*specgen.ImageVolume, error) {
 	volMap := make(map[string]*specgen.ImageVolume)
 
 	ctrAnnotation := define.KubeImageAutomountAnnotation + "/" + forContainer
@@ -142,7 +361,7 @@ func (ic *ContainerEngine) prepareAutomountImages(ctx context.Context, forContai
 			return nil, fmt.Errorf("image %s from container %s does not exist in local storage, cannot automount: %w", imageName, forContainer, err)
 		}
 
-		logrus.Infof("Resolved image name %s to %s for automount into container %s", imageName, fullName, forContainer)
+		logger.Infof("Resolved image name %s to %s for automount into container %s", imageName, fullName, forContainer)
 
 		inspect, err := img.Inspect(ctx, nil)
 		if err != nil {
@@ -153,7 +372,7 @@ func (ic *ContainerEngine) prepareAutomountImages(ctx context.Context, forContai
 
 		for path := range volumes {
 			if oldPath, ok := volMap[path]; ok && oldPath != nil {
-				logrus.Warnf("Multiple volume mounts to %q requested, overriding image %q with image %s", path, oldPath.Source, fullName)
+				logger.Warnf("Multiple volume mounts to %q requested, overriding image %q with image %s", path, oldPath.Source, fullName)
 			}
 
 			imgVol := new(specgen.ImageVolume)
@@ -230,28 +449,33 @@ func k8sName(content []byte, suffix string) string {
 	return hash[0:12] + "-" + suffix
 }
 
-func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options entities.PlayKubeOptions) (_ *entities.PlayKubeReport, finalErr error) {
+// playLogger returns the logger for the messages of a play.
+func playLogger(options entities.PlayKubeOptions) *logrus.Entry {
+	if options.Logger != nil {
+		return options.Logger
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options entities.PlayKubeOptions) (playReport *entities.PlayKubeReport, finalErr error) {
 	if options.ServiceContainer && options.Start == types.OptionalBoolFalse { // Sanity check to be future proof
 		return nil, fmt.Errorf("running a service container requires starting the pod(s)")
 	}
 
-	report := &entities.PlayKubeReport{}
+	report := &entities.PlayKubeReport{DryRun: options.DryRun, Namespace: options.Namespace}
+	// When the context is canceled or times out, or the request was rolled
+	// back, hand back what was created so far so that the caller can report
+	// it.
+	defer func() {
+		if finalErr != nil && (ctx.Err() != nil || report.RolledBack) {
+			playReport = report
+		}
+	}()
 	validKinds := 0
 
-	// when no network options are specified, create a common network for all the pods
-	if len(options.Networks) == 0 {
-		_, err := ic.NetworkCreate(
-			ctx,
-			nettypes.Network{
-				Name:       kubeDefaultNetwork,
-				DNSEnabled: true,
-			},
-			&nettypes.NetworkCreateOptions{
-				IgnoreIfExists: true,
-			},
-		)
-		if err != nil {
-			return nil, err
+	if !options.UseLongAnnotations {
+		if keys := annotations.LongValues(options.Annotations); len(keys) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("values of annotations %s are longer than %d characters and may be truncated, use long annotations to keep them", strings.Join(keys, ", "), define.MaxKubeAnnotation))
 		}
 	}
 
@@ -267,12 +491,83 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 		return nil, err
 	}
 
+	if options.StrictValidation {
+		if err := validateKubeDocuments(documentList); err != nil {
+			return nil, err
+		}
+	}
+
 	// sort kube kinds
 	documentList, err = sortKubeKinds(documentList)
 	if err != nil {
 		return nil, fmt.Errorf("unable to sort kube kinds: %w", err)
 	}
 
+	buildWarnings, err := checkBuildContext(documentList, options)
+	if err != nil {
+		return nil, err
+	}
+	report.Warnings = append(report.Warnings, buildWarnings...)
+
+	if options.DenyPrivileged {
+		privileged, err := privilegedContainers(documentList)
+		if err != nil {
+			return nil, err
+		}
+		if len(privileged) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrPrivilegedNotAllowed, strings.Join(privileged, ", "))
+		}
+	}
+
+	if options.RequireDigest {
+		images, err := kubeContainerImages(documentList)
+		if err != nil {
+			return nil, err
+		}
+		if unpinned := unpinnedImages(images); len(unpinned) > 0 {
+			return nil, fmt.Errorf("%w: not pinned to a digest: %s", ErrImageNotAllowed, strings.Join(unpinned, ", "))
+		}
+	}
+
+	if options.Hostname != "" {
+		numPods, err := countKubePods(documentList)
+		if err != nil {
+			return nil, err
+		}
+		if numPods > 1 {
+			return nil, fmt.Errorf("cannot set the hostname of %d pods, the YAML must describe a single pod", numPods)
+		}
+	}
+
+	// when no network options are specified, create a common network for all the pods.
+	// It is created once the documents are validated, a rejected request leaves nothing behind.
+	if len(options.Networks) == 0 && !options.DryRun {
+		_, err := ic.NetworkCreate(
+			ctx,
+			nettypes.Network{
+				Name:       kubeDefaultNetwork,
+				DNSEnabled: true,
+			},
+			&nettypes.NetworkCreateOptions{
+				IgnoreIfExists: true,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.BuildParallelism > 1 && !options.DryRun {
+		builds, err := ic.buildImages(ctx, documentList, options)
+		if err != nil {
+			return nil, err
+		}
+		report.Builds = builds
+		// The images are up to date, do not build them again for each
+		// container using them.
+		options.Build = types.OptionalBoolFalse
+	}
+
 	ipIndex := 0
 
 	var configMaps []v1.ConfigMap
@@ -306,6 +601,13 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 	// maintainable long term.
 	var serviceContainer *libpod.Container
 	var notifyProxies []*notifyproxy.NotifyProxy
+	if options.Atomic && !options.DryRun {
+		defer func() {
+			if finalErr != nil {
+				ic.rollbackPlayKube(context.WithoutCancel(ctx), playLogger(options), report, serviceContainer)
+			}
+		}()
+	}
 	defer func() {
 		// Close the notify proxy on return.  At that point we know
 		// that a) all containers have send their READY message and
@@ -313,7 +615,7 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 		// containers).
 		for _, proxy := range notifyProxies {
 			if err := proxy.Close(); err != nil {
-				logrus.Errorf("Closing notify proxy %q: %v", proxy.SocketPath(), err)
+				playLogger(options).Errorf("Closing notify proxy %q: %v", proxy.SocketPath(), err)
 			}
 		}
 	}()
@@ -327,7 +629,7 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 		}
 
 		// TODO: create constants for the various "kinds" of yaml files.
-		if options.ServiceContainer && serviceContainer == nil && (kind == "Pod" || kind == "Deployment") {
+		if options.ServiceContainer && !options.DryRun && serviceContainer == nil && (kind == "Pod" || kind == "Deployment") {
 			ctr, err := ic.createServiceContainer(ctx, k8sName(content, "service"), options)
 			if err != nil {
 				return nil, err
@@ -344,7 +646,7 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 					// This can happen when an error happens during kube play and we are trying to
 					// clean up after the error. The service container will be removed as part of the
 					// teardown function.
-					logrus.Debugf("Error cleaning up service container after failure: %v", err)
+					playLogger(options).Debugf("Error cleaning up service container after failure: %v", err)
 				}
 			}()
 		}
@@ -379,6 +681,12 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 			notifyProxies = append(notifyProxies, proxies...)
 
 			report.Pods = append(report.Pods, r.Pods...)
+			for _, image := range r.Pulls {
+				report.Pulls = appendPull(report.Pulls, image)
+			}
+			for _, build := range r.Builds {
+				report.Builds = appendBuild(report.Builds, build.Image)
+			}
 			validKinds++
 			setRanContainers(r)
 		case "DaemonSet":
@@ -395,6 +703,12 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 			notifyProxies = append(notifyProxies, proxies...)
 
 			report.Pods = append(report.Pods, r.Pods...)
+			for _, image := range r.Pulls {
+				report.Pulls = appendPull(report.Pulls, image)
+			}
+			for _, build := range r.Builds {
+				report.Builds = appendBuild(report.Builds, build.Image)
+			}
 			validKinds++
 			setRanContainers(r)
 		case "Deployment":
@@ -411,6 +725,12 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 			notifyProxies = append(notifyProxies, proxies...)
 
 			report.Pods = append(report.Pods, r.Pods...)
+			for _, image := range r.Pulls {
+				report.Pulls = appendPull(report.Pulls, image)
+			}
+			for _, build := range r.Builds {
+				report.Builds = appendBuild(report.Builds, build.Image)
+			}
 			validKinds++
 			setRanContainers(r)
 		case "Job":
@@ -427,6 +747,12 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 			notifyProxies = append(notifyProxies, proxies...)
 
 			report.Pods = append(report.Pods, r.Pods...)
+			for _, image := range r.Pulls {
+				report.Pulls = appendPull(report.Pulls, image)
+			}
+			for _, build := range r.Builds {
+				report.Builds = appendBuild(report.Builds, build.Image)
+			}
 			validKinds++
 			setRanContainers(r)
 		case "PersistentVolumeClaim":
@@ -449,7 +775,11 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 				}
 			}
 
-			r, err := ic.playKubePVC(ctx, "", &pvcYAML)
+			if pvcYAML.Name != "" {
+				pvcYAML.Name = options.NamePrefix + pvcYAML.Name
+			}
+
+			r, err := ic.playKubePVC(ctx, "", &pvcYAML, options)
 			if err != nil {
 				return nil, err
 			}
@@ -470,14 +800,14 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 				return nil, fmt.Errorf("unable to read YAML as kube secret: %w", err)
 			}
 
-			r, err := ic.playKubeSecret(&secret)
+			r, err := ic.playKubeSecret(&secret, options.DryRun)
 			if err != nil {
 				return nil, err
 			}
-			report.Secrets = append(report.Secrets, entities.PlaySecret{CreateReport: r})
+			report.Secrets = append(report.Secrets, entities.PlaySecret{Name: secret.Name, CreateReport: r})
 			validKinds++
 		default:
-			logrus.Infof("Kube kind %s not supported", kind)
+			playLogger(options).Infof("Kube kind %s not supported", kind)
 			continue
 		}
 	}
@@ -489,10 +819,29 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 		return nil, fmt.Errorf("YAML document does not contain any supported kube kind")
 	}
 
-	if !options.ServiceContainer {
+	if options.WaitReady && options.Start != types.OptionalBoolFalse && !options.DryRun {
+		if err := ic.waitPlayKubeReady(ctx, report.Pods); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the pods are namespaced, the volumes, including those of the
+	// configmaps, and the secrets keep the names of the YAML.
+	if options.Namespace != "" && (len(report.Volumes) > 0 || len(report.Secrets) > 0) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("volumes and secrets are not created in namespace %s, they are shared with the other namespaces", options.Namespace))
+	}
+
+	report.EffectiveTLSVerify = effectiveTLSVerify(options.SkipTLSVerify, report.Pulls, ic.Libpod.SystemContext())
+
+	if !options.ServiceContainer || options.DryRun {
 		return report, nil
 	}
 
+	if serviceContainer != nil {
+		report.ServiceContainerID = serviceContainer.ID()
+		report.ServiceContainerName = serviceContainer.Name()
+	}
+
 	// If we started containers along with a service container, we are
 	// running inside a systemd unit and need to set the main PID.
 
@@ -527,19 +876,230 @@ func (ic *ContainerEngine) PlayKube(ctx context.Context, body io.Reader, options
 			}
 			report.ExitCode = &exitCode
 		}
-
-		report.ServiceContainerID = serviceContainer.ID()
 	} else if serviceContainer != nil {
 		// No containers started, make sure to stop the service container.
 		// Note because the pods still do exists and are not removed by default we cannot remove it.
 		if err := serviceContainer.StopWithTimeout(0); err != nil {
-			logrus.Errorf("Failed to stop service container: %v", err)
+			playLogger(options).Errorf("Failed to stop service container: %v", err)
 		}
 	}
 
 	return report, nil
 }
 
+// waitPlayKubeReady waits for the containers of pods with a healthcheck to be
+// healthy and for the others to be running or to have run to completion. It
+// fails as soon as a container turns unhealthy or exits with an error, and is
+// bounded by the deadline of ctx.
+func (ic *ContainerEngine) waitPlayKubeReady(ctx context.Context, pods []entities.PlayKubePod) error {
+	for _, pod := range pods {
+		for _, id := range pod.Containers {
+			ctr, err := ic.Libpod.LookupContainer(id)
+			if err != nil {
+				return err
+			}
+			if ctr.HasHealthCheck() {
+				if _, err := ctr.WaitForConditionWithInterval(ctx, waitReadyInterval, define.HealthCheckHealthy, define.HealthCheckUnhealthy); err != nil {
+					return fmt.Errorf("waiting for container %s to be healthy: %w", ctr.Name(), err)
+				}
+				status, err := ctr.HealthCheckStatus()
+				if err != nil {
+					return err
+				}
+				if status != define.HealthCheckHealthy {
+					return fmt.Errorf("container %s is %s", ctr.Name(), status)
+				}
+				continue
+			}
+			if _, err := ctr.WaitForConditionWithInterval(ctx, waitReadyInterval, define.ContainerStateRunning.String(), define.ContainerStateExited.String()); err != nil {
+				return fmt.Errorf("waiting for container %s to be running: %w", ctr.Name(), err)
+			}
+			state, err := ctr.State()
+			if err != nil {
+				return err
+			}
+			var exitCode int32
+			if state == define.ContainerStateExited {
+				if exitCode, _, err = ctr.ExitCode(); err != nil {
+					return err
+				}
+			}
+			if err := checkReadyState(ctr.Name(), state, exitCode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkReadyState fails unless a container without a healthcheck is ready:
+// running, or exited successfully as a container run to completion does.
+func checkReadyState(name string, state define.ContainerStatus, exitCode int32) error {
+	switch {
+	case state == define.ContainerStateRunning:
+		return nil
+	case state == define.ContainerStateExited && exitCode == 0:
+		return nil
+	case state == define.ContainerStateExited:
+		return fmt.Errorf("container %s exited with code %d", name, exitCode)
+	}
+	return fmt.Errorf("container %s is %s", name, state)
+}
+
+// rollbackPlayKube removes the pods, volumes and secrets listed in report as
+// well as the service container, recording the outcome in the teardown
+// reports of report.
+func (ic *ContainerEngine) rollbackPlayKube(ctx context.Context, logger *logrus.Entry, report *entities.PlayKubeReport, serviceContainer *libpod.Container) {
+	podIDs := make([]string, 0, len(report.Pods))
+	for _, pod := range report.Pods {
+		// Pods played before are not part of what the request created.
+		if pod.Action == entities.PlayKubePodRestarted || pod.Action == entities.PlayKubePodUnchanged {
+			continue
+		}
+		podIDs = append(podIDs, pod.ID)
+	}
+	if len(podIDs) > 0 {
+		rmReports, err := ic.PodRm(ctx, podIDs, entities.PodRmOptions{Force: true, Ignore: true})
+		if err != nil {
+			logger.Errorf("Rolling back pods: %v", err)
+		}
+		report.RmReport = rmReports
+	}
+
+	if serviceContainer != nil {
+		if err := ic.Libpod.RemoveContainer(ctx, serviceContainer, true, true, nil); err != nil && !errors.Is(err, define.ErrNoSuchCtr) && !errors.Is(err, define.ErrCtrRemoved) {
+			logger.Errorf("Rolling back service container: %v", err)
+		}
+	}
+
+	secretIDs := make([]string, 0, len(report.Secrets))
+	for _, secret := range report.Secrets {
+		secretIDs = append(secretIDs, secret.CreateReport.ID)
+	}
+	if len(secretIDs) > 0 {
+		secretRmReports, err := ic.SecretRm(ctx, secretIDs, entities.SecretRmOptions{Ignore: true})
+		if err != nil {
+			logger.Errorf("Rolling back secrets: %v", err)
+		}
+		report.SecretRmReport = secretRmReports
+	}
+
+	volumeNames := make([]string, 0, len(report.Volumes))
+	for _, volume := range report.Volumes {
+		volumeNames = append(volumeNames, volume.Name)
+	}
+	if len(volumeNames) > 0 {
+		volumeRmReports, err := ic.VolumeRm(ctx, volumeNames, entities.VolumeRmOptions{Force: true, Ignore: true})
+		if err != nil {
+			logger.Errorf("Rolling back volumes: %v", err)
+		}
+		report.VolumeRmReport = volumeRmReports
+	}
+
+	report.RolledBack = true
+}
+
+// restartKubePod restarts the containers of a pod played before with the same
+// spec, or leaves it as is when the pods are not started. A dry run reports the
+// pod without restarting it.
+func (ic *ContainerEngine) restartKubePod(ctx context.Context, pod *libpod.Pod, options entities.PlayKubeOptions) (*entities.PlayKubeReport, []*notifyproxy.NotifyProxy, error) {
+	playKubePod := entities.PlayKubePod{ID: pod.ID(), Name: pod.Name(), Action: entities.PlayKubePodUnchanged}
+	if options.Start != types.OptionalBoolFalse && options.DryRun {
+		playKubePod.Action = entities.PlayKubePodRestarted
+	} else if options.Start != types.OptionalBoolFalse {
+		ctrErrs, err := pod.Restart(ctx)
+		if err != nil && !errors.Is(err, define.ErrPodPartialFail) {
+			return nil, nil, fmt.Errorf("restarting pod %s: %w", pod.Name(), err)
+		}
+		for id, err := range ctrErrs {
+			playKubePod.ContainerErrors = append(playKubePod.ContainerErrors, fmt.Errorf("restarting container %s: %w", id, err).Error())
+		}
+		slices.Sort(playKubePod.ContainerErrors)
+		playKubePod.Action = entities.PlayKubePodRestarted
+		playKubePod.Networks, err = kubePodNetworks(pod)
+		if err != nil {
+			playKubePod.Logs = append(playKubePod.Logs, fmt.Sprintf("retrieving the addresses of pod %s: %v", pod.Name(), err))
+		}
+	}
+
+	ctrs, err := pod.AllContainers()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, ctr := range ctrs {
+		switch {
+		case ctr.IsInfra():
+		case ctr.IsInitCtr():
+			playKubePod.InitContainers = append(playKubePod.InitContainers, ctr.ID())
+			playKubePod.InitContainerNames = append(playKubePod.InitContainerNames, ctr.Name())
+		default:
+			playKubePod.Containers = append(playKubePod.Containers, ctr.ID())
+			playKubePod.ContainerNames = append(playKubePod.ContainerNames, ctr.Name())
+		}
+	}
+
+	return &entities.PlayKubeReport{Pods: []entities.PlayKubePod{playKubePod}}, nil, nil
+}
+
+// kubePodNetworks returns the addresses of the started pod in each network,
+// as seen by the container holding its network namespace.
+func kubePodNetworks(pod *libpod.Pod) ([]entities.PlayKubePodNetwork, error) {
+	var ctr *libpod.Container
+	if pod.HasInfraContainer() {
+		infra, err := pod.InfraContainer()
+		if err != nil {
+			return nil, err
+		}
+		ctr = infra
+	} else {
+		ctrs, err := pod.AllContainers()
+		if err != nil {
+			return nil, err
+		}
+		if len(ctrs) == 0 {
+			return nil, nil
+		}
+		ctr = ctrs[0]
+	}
+	data, err := ctr.Inspect(false)
+	if err != nil {
+		return nil, err
+	}
+	if data.NetworkSettings == nil {
+		return nil, nil
+	}
+	return playKubeNetworks(data.NetworkSettings.Networks), nil
+}
+
+// playKubeNetworks lists the addresses of each network, sorted by name.
+// Networks without an address, like pasta or slirp4netns, are left out.
+func playKubeNetworks(networks map[string]*define.InspectAdditionalNetwork) []entities.PlayKubePodNetwork {
+	var result []entities.PlayKubePodNetwork
+	for _, name := range slices.Sorted(maps.Keys(networks)) {
+		network := networks[name]
+		if network == nil {
+			continue
+		}
+		var ips []string
+		if network.IPAddress != "" {
+			ips = append(ips, network.IPAddress)
+		}
+		for _, addr := range network.SecondaryIPAddresses {
+			ips = append(ips, addr.Addr)
+		}
+		if network.GlobalIPv6Address != "" {
+			ips = append(ips, network.GlobalIPv6Address)
+		}
+		for _, addr := range network.SecondaryIPv6Addresses {
+			ips = append(ips, addr.Addr)
+		}
+		if len(ips) > 0 {
+			result = append(result, entities.PlayKubePodNetwork{Name: name, IPs: ips})
+		}
+	}
+	return result
+}
+
 func (ic *ContainerEngine) playKubeDaemonSet(ctx context.Context, daemonSetYAML *v1apps.DaemonSet, options entities.PlayKubeOptions, ipIndex *int, configMaps []v1.ConfigMap, serviceContainer *libpod.Container) (*entities.PlayKubeReport, []*notifyproxy.NotifyProxy, error) {
 	var (
 		daemonSetName string
@@ -559,6 +1119,8 @@ func (ic *ContainerEngine) playKubeDaemonSet(ctx context.Context, daemonSetYAML
 		return nil, nil, fmt.Errorf("encountered while bringing up pod %s: %w", podName, err)
 	}
 	report.Pods = podReport.Pods
+	report.Pulls = podReport.Pulls
+	report.Builds = podReport.Builds
 
 	return &report, proxies, nil
 }
@@ -580,7 +1142,7 @@ func (ic *ContainerEngine) playKubeDeployment(ctx context.Context, deploymentYAM
 		numReplicas = *deploymentYAML.Spec.Replicas
 	}
 	if numReplicas > 1 {
-		logrus.Warnf("Limiting replica count to 1, more than one replica is not supported by Podman")
+		playLogger(options).Warnf("Limiting replica count to 1, more than one replica is not supported by Podman")
 	}
 	podSpec = deploymentYAML.Spec.Template
 
@@ -590,6 +1152,8 @@ func (ic *ContainerEngine) playKubeDeployment(ctx context.Context, deploymentYAM
 		return nil, nil, fmt.Errorf("encountered while bringing up pod %s: %w", podName, err)
 	}
 	report.Pods = podReport.Pods
+	report.Pulls = podReport.Pulls
+	report.Builds = podReport.Builds
 
 	return &report, proxies, nil
 }
@@ -613,11 +1177,13 @@ func (ic *ContainerEngine) playKubeJob(ctx context.Context, jobYAML *v1.Job, opt
 		return nil, nil, fmt.Errorf("encountered while bringing up pod %s: %w", podName, err)
 	}
 	report.Pods = podReport.Pods
+	report.Pulls = podReport.Pulls
+	report.Builds = podReport.Builds
 
 	return &report, proxies, nil
 }
 
-func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podYAML *v1.PodTemplateSpec, options entities.PlayKubeOptions, ipIndex *int, annotations map[string]string, configMaps []v1.ConfigMap, serviceContainer *libpod.Container) (*entities.PlayKubeReport, []*notifyproxy.NotifyProxy, error) {
+func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podYAML *v1.PodTemplateSpec, options entities.PlayKubeOptions, ipIndex *int, annotations map[string]string, configMaps []v1.ConfigMap, serviceContainer *libpod.Container) (_ *entities.PlayKubeReport, _ []*notifyproxy.NotifyProxy, finalErr error) {
 	cfg, err := ic.Libpod.GetConfigNoCopy()
 	if err != nil {
 		return nil, nil, err
@@ -645,19 +1211,103 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		return nil, nil, fmt.Errorf("pod does not have a name")
 	}
 
+	ceilings, err := resourceCeilings(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range podYAML.Spec.InitContainers {
+		capResources(&podYAML.Spec.InitContainers[i], ceilings)
+		addEnv(&podYAML.Spec.InitContainers[i], options.Env)
+	}
+	for i := range podYAML.Spec.Containers {
+		capResources(&podYAML.Spec.Containers[i], ceilings)
+		addEnv(&podYAML.Spec.Containers[i], options.Env)
+	}
+
+	// Annotations refer to the pod by the name it has in the YAML.
+	yamlPodName := podName
+	if options.Namespace != "" {
+		podName = namespacedPodName(options.Namespace, podName)
+		if podYAML.Labels == nil {
+			podYAML.Labels = make(map[string]string)
+		}
+		podYAML.Labels[kubeNamespaceLabel] = options.Namespace
+	}
+	podName = options.NamePrefix + podName
+	// The claims are created with the prefix, so refer to them by it.
+	for _, vol := range podYAML.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName != "" {
+			vol.PersistentVolumeClaim.ClaimName = options.NamePrefix + vol.PersistentVolumeClaim.ClaimName
+		}
+	}
+
 	if _, ok := annotations[define.VolumesFromAnnotation]; ok {
 		return nil, nil, fmt.Errorf("annotation %s without target volume is reserved for internal use", define.VolumesFromAnnotation)
 	}
 
+	if options.Hostname != "" {
+		podYAML.Spec.Hostname = options.Hostname
+	}
+
+	if options.Restart {
+		specDigest, err := kubePodDigest(podYAML, annotations, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		existing, err := ic.Libpod.LookupPod(podName)
+		switch {
+		case errors.Is(err, define.ErrNoSuchPod):
+			playKubePod.Action = entities.PlayKubePodCreated
+		case err != nil:
+			return nil, nil, err
+		case existing.Labels()[kubeSpecDigestLabel] == specDigest:
+			return ic.restartKubePod(ctx, existing, options)
+		default:
+			if existing.Labels()[kubeSpecDigestLabel] == "" {
+				// Played without Restart, its spec is unknown and cannot be
+				// told unchanged.
+				msg := fmt.Sprintf("spec of pod %s is unknown as it was not played with restart, recreating it", podName)
+				playLogger(options).Warn(msg)
+				playKubePod.Logs = append(playKubePod.Logs, msg)
+			}
+			if !options.DryRun {
+				if _, err := ic.PodRm(ctx, []string{podName}, entities.PodRmOptions{Force: true, Ignore: true}); err != nil {
+					return nil, nil, fmt.Errorf("recreating pod %v: %w", podName, err)
+				}
+			}
+			playKubePod.Action = entities.PlayKubePodRecreated
+		}
+		if podYAML.Labels == nil {
+			podYAML.Labels = make(map[string]string)
+		}
+		podYAML.Labels[kubeSpecDigestLabel] = specDigest
+	}
+
 	podOpt := entities.PodCreateOptions{
 		Infra:      true,
-		Net:        &entities.NetOptions{NoHosts: options.NoHosts, NoHostname: options.NoHostname},
+		Net:        &entities.NetOptions{NoHosts: options.NoHosts, NoHostname: options.NoHostname, AddHosts: slices.Clone(options.AddHost)},
 		ExitPolicy: string(config.PodExitPolicyStop),
 	}
 	podOpt, err = kube.ToPodOpt(ctx, podName, podOpt, options.PublishAllPorts, podYAML)
 	if err != nil {
 		return nil, nil, err
 	}
+	if len(options.Sysctl) > 0 {
+		podOpt.Sysctl, err = mergeSysctls(podOpt.Sysctl, options.Sysctl, podYAML.Spec)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// The DNS options replace the DNS config of the YAML.
+	if len(options.DNSServers) > 0 {
+		podOpt.Net.DNSServers = options.DNSServers
+	}
+	if len(options.DNSSearch) > 0 {
+		podOpt.Net.DNSSearch = options.DNSSearch
+	}
+	if len(options.DNSOptions) > 0 {
+		podOpt.Net.DNSOptions = options.DNSOptions
+	}
 
 	// add kube default network if no network is explicitly added
 	if podOpt.Net.Network.NSMode != "host" && len(options.Networks) == 0 {
@@ -678,7 +1328,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 	if options.Userns == "" {
 		if v, ok := annotations[define.UserNsAnnotation]; ok {
 			options.Userns = v
-		} else if v, ok := annotations[define.UserNsAnnotation+"/"+podName]; ok {
+		} else if v, ok := annotations[define.UserNsAnnotation+"/"+yamlPodName]; ok {
 			options.Userns = v
 		} else if podYAML.Spec.HostUsers != nil && !*podYAML.Spec.HostUsers {
 			options.Userns = "auto"
@@ -690,7 +1340,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			options.Userns = "auto"
 		}
 	} else if podYAML.Spec.HostUsers != nil {
-		logrus.Info("overriding the user namespace mode in the pod spec")
+		playLogger(options).Info("overriding the user namespace mode in the pod spec")
 	}
 
 	// Validate the userns modes supported.
@@ -713,7 +1363,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		}
 	} else if len(options.StaticIPs) > 0 {
 		// only warn if the user has set at least one ip
-		logrus.Warn("No more static ips left using a random one")
+		playLogger(options).Warn("No more static ips left using a random one")
 	}
 	if len(options.StaticMACs) > *ipIndex {
 		if !podOpt.Net.Network.IsBridge() {
@@ -728,7 +1378,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		}
 	} else if len(options.StaticIPs) > 0 {
 		// only warn if the user has set at least one mac
-		logrus.Warn("No more static macs left using a random one")
+		playLogger(options).Warn("No more static macs left using a random one")
 	}
 	*ipIndex++
 
@@ -787,6 +1437,10 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 	// defined by a configmap or secret
 	for _, v := range volumes {
 		if (v.Type == kube.KubeVolumeTypeConfigMap || v.Type == kube.KubeVolumeTypeSecret) && !v.Optional {
+			v.Source = options.NamePrefix + v.Source
+			if options.DryRun {
+				continue
+			}
 			volumeOptions := []libpod.VolumeCreateOption{
 				libpod.WithVolumeName(v.Source),
 				libpod.WithVolumeMountLabel(mountLabel),
@@ -836,10 +1490,16 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 				}
 			}
 
-			_, err := ic.buildOrPullImage(ctx, cwd, writer, v.Source, v.ImagePullPolicy, options)
+			_, origin, err := ic.buildOrPullImage(ctx, cwd, writer, v.Source, v.ImagePullPolicy, options)
 			if err != nil {
 				return nil, nil, err
 			}
+			switch origin {
+			case imagePulled:
+				report.Pulls = appendPull(report.Pulls, v.Source)
+			case imageBuilt:
+				report.Builds = appendBuild(report.Builds, v.Source)
+			}
 		}
 	}
 
@@ -860,6 +1520,10 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		podSpec.PodSpecGen.RestartPolicy = define.RestartPolicyAlways
 	}
 
+	if options.CgroupParent != "" {
+		podSpec.PodSpecGen.CgroupParent = options.CgroupParent
+	}
+
 	if podOpt.Infra {
 		infraImage := cfg.Engine.InfraImage
 		infraOptions := entities.NewInfraContainerCreateOptions()
@@ -893,33 +1557,58 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		if container.Name != "" {
 			ctrNameAliases = append(ctrNameAliases, container.Name)
 		}
-	}
-	for k, v := range podSpec.PodSpecGen.Networks {
-		v.Aliases = append(v.Aliases, ctrNameAliases...)
-		podSpec.PodSpecGen.Networks[k] = v
-	}
-
-	if serviceContainer != nil {
-		podSpec.PodSpecGen.ServiceContainerID = serviceContainer.ID()
-	}
+	}
+	for k, v := range podSpec.PodSpecGen.Networks {
+		v.Aliases = append(v.Aliases, ctrNameAliases...)
+		podSpec.PodSpecGen.Networks[k] = v
+	}
+
+	if serviceContainer != nil {
+		podSpec.PodSpecGen.ServiceContainerID = serviceContainer.ID()
+	}
+
+	// A dry run stops short of creating the pod and its containers, they
+	// are only reported by name.
+	var (
+		pod        *libpod.Pod
+		podID      string
+		podInfraID string
+	)
+	if !options.DryRun {
+		if options.Replace {
+			if _, err := ic.PodRm(ctx, []string{podName}, entities.PodRmOptions{Force: true, Ignore: true}); err != nil {
+				return nil, nil, fmt.Errorf("replacing pod %v: %w", podName, err)
+			}
+		}
+		// Create the Pod
+		pod, err = generate.MakePod(&podSpec, ic.Libpod)
+		if err != nil {
+			return nil, nil, err
+		}
+		if options.Atomic {
+			// The pod is only reported once fully set up, remove it here so
+			// that the caller can roll back what it knows about.
+			defer func() {
+				if finalErr == nil {
+					return
+				}
+				if _, err := ic.PodRm(context.WithoutCancel(ctx), []string{pod.ID()}, entities.PodRmOptions{Force: true, Ignore: true}); err != nil {
+					playLogger(options).Errorf("Rolling back pod %s: %v", pod.Name(), err)
+				}
+			}()
+		}
 
-	if options.Replace {
-		if _, err := ic.PodRm(ctx, []string{podName}, entities.PodRmOptions{Force: true, Ignore: true}); err != nil {
-			return nil, nil, fmt.Errorf("replacing pod %v: %w", podName, err)
+		podID = pod.ID()
+		podInfraID, err = pod.InfraContainerID()
+		if err != nil {
+			return nil, nil, err
 		}
 	}
-	// Create the Pod
-	pod, err := generate.MakePod(&podSpec, ic.Libpod)
-	if err != nil {
-		return nil, nil, err
-	}
 
-	podInfraID, err := pod.InfraContainerID()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if !options.Quiet {
+	switch {
+	case options.Writer != nil:
+		writer = options.Writer
+	case !options.Quiet:
 		writer = os.Stderr
 	}
 
@@ -952,10 +1641,16 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		if initCtr.Lifecycle != nil || initCtr.LivenessProbe != nil || initCtr.ReadinessProbe != nil || initCtr.StartupProbe != nil {
 			return nil, nil, fmt.Errorf("cannot create an init container that has either of lifecycle, livenessProbe, readinessProbe, or startupProbe set")
 		}
-		pulledImage, labels, err := ic.getImageAndLabelInfo(ctx, cwd, annotations, writer, initCtr, options)
+		pulledImage, labels, origin, err := ic.getImageAndLabelInfo(ctx, cwd, annotations, writer, initCtr, options)
 		if err != nil {
 			return nil, nil, err
 		}
+		switch origin {
+		case imagePulled:
+			report.Pulls = appendPull(report.Pulls, initCtr.Image)
+		case imageBuilt:
+			report.Builds = appendBuild(report.Builds, initCtr.Image)
+		}
 
 		// add podYAML labels
 		maps.Copy(labels, podSpec.PodSpecGen.Labels)
@@ -964,7 +1659,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			initCtrType = define.OneShotInitContainer
 		}
 
-		automountImages, err := ic.prepareAutomountImages(ctx, initCtr.Name, annotations)
+		automountImages, err := ic.prepareAutomountImages(ctx, playLogger(options), initCtr.Name, annotations)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -986,7 +1681,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			LogDriver:          options.LogDriver,
 			LogOptions:         options.LogOptions,
 			NetNSIsHost:        p.NetNS.IsHost(),
-			PodID:              pod.ID(),
+			PodID:              podID,
 			PodInfraID:         podInfraID,
 			PodName:            podName,
 			PodSecurityContext: podYAML.Spec.SecurityContext,
@@ -1004,6 +1699,10 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		if err != nil {
 			return nil, nil, err
 		}
+		if options.DryRun {
+			playKubePod.InitContainerNames = append(playKubePod.InitContainerNames, specGen.Name)
+			continue
+		}
 
 		// ensure the environment is setup for initContainers as well: https://github.com/containers/podman/issues/18384
 		warn, err := generate.CompleteSpec(ctx, ic.Libpod, specGen)
@@ -1011,7 +1710,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			return nil, nil, err
 		}
 		for _, w := range warn {
-			logrus.Warn(w)
+			playLogger(options).Warn(w)
 		}
 
 		specGen.SdNotifyMode = define.SdNotifyModeIgnore
@@ -1045,15 +1744,21 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		}
 
 		ctrNames[container.Name] = ""
-		pulledImage, labels, err := ic.getImageAndLabelInfo(ctx, cwd, annotations, writer, container, options)
+		pulledImage, labels, origin, err := ic.getImageAndLabelInfo(ctx, cwd, annotations, writer, container, options)
 		if err != nil {
 			return nil, nil, err
 		}
+		switch origin {
+		case imagePulled:
+			report.Pulls = appendPull(report.Pulls, container.Image)
+		case imageBuilt:
+			report.Builds = appendBuild(report.Builds, container.Image)
+		}
 
 		// add podYAML labels
 		maps.Copy(labels, podSpec.PodSpecGen.Labels)
 
-		automountImages, err := ic.prepareAutomountImages(ctx, container.Name, annotations)
+		automountImages, err := ic.prepareAutomountImages(ctx, playLogger(options), container.Name, annotations)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1076,7 +1781,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			LogOptions:         options.LogOptions,
 			NetNSIsHost:        p.NetNS.IsHost(),
 			PidNSIsHost:        p.Pid.IsHost(),
-			PodID:              pod.ID(),
+			PodID:              podID,
 			PodInfraID:         podInfraID,
 			PodName:            podName,
 			PodSecurityContext: podYAML.Spec.SecurityContext,
@@ -1090,6 +1795,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			ImageVolumes:       automountImages,
 			UtsNSIsHost:        p.UtsNs.IsHost(),
 			NoPodPrefix:        options.NoPodPrefix,
+			NamePrefix:         options.NamePrefix,
 		}
 
 		if podYAML.Spec.TerminationGracePeriodSeconds != nil {
@@ -1100,6 +1806,10 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		if err != nil {
 			return nil, nil, err
 		}
+		if options.DryRun {
+			playKubePod.ContainerNames = append(playKubePod.ContainerNames, specGen.Name)
+			continue
+		}
 
 		// Make sure to complete the spec (#17016)
 		warn, err := generate.CompleteSpec(ctx, ic.Libpod, specGen)
@@ -1107,7 +1817,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 			return nil, nil, err
 		}
 		for _, w := range warn {
-			logrus.Warn(w)
+			playLogger(options).Warn(w)
 		}
 
 		specGen.RawImageName = container.Image
@@ -1158,7 +1868,7 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		containers = append(containers, ctr)
 	}
 
-	if options.Start != types.OptionalBoolFalse {
+	if options.Start != types.OptionalBoolFalse && !options.DryRun {
 		// Start the containers
 		podStartErrors, err := pod.Start(ctx)
 		if err != nil && !errors.Is(err, define.ErrPodPartialFail) {
@@ -1195,12 +1905,21 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 		}
 	}
 
-	playKubePod.ID = pod.ID()
+	playKubePod.ID = podID
+	playKubePod.Name = podName
 	for _, ctr := range containers {
 		playKubePod.Containers = append(playKubePod.Containers, ctr.ID())
+		playKubePod.ContainerNames = append(playKubePod.ContainerNames, ctr.Name())
+	}
+	if options.Start != types.OptionalBoolFalse && !options.DryRun {
+		playKubePod.Networks, err = kubePodNetworks(pod)
+		if err != nil {
+			playKubePod.Logs = append(playKubePod.Logs, fmt.Sprintf("retrieving the addresses of pod %s: %v", pod.Name(), err))
+		}
 	}
 	for _, initCtr := range initContainers {
 		playKubePod.InitContainers = append(playKubePod.InitContainers, initCtr.ID())
+		playKubePod.InitContainerNames = append(playKubePod.InitContainerNames, initCtr.Name())
 	}
 
 	report.Pods = append(report.Pods, playKubePod)
@@ -1212,22 +1931,27 @@ func (ic *ContainerEngine) playKubePod(ctx context.Context, podName string, podY
 //   - A folder with the name of the image exists in current directory
 //   - A Dockerfile or Containerfile exists in that folder
 //   - The image doesn't exist locally OR the user explicitly provided the option `--build`
-func (ic *ContainerEngine) buildImageFromContainerfile(ctx context.Context, cwd string, writer io.Writer, image string, options entities.PlayKubeOptions) (*libimage.Image, error) {
-	buildFile, err := getBuildFile(image, cwd)
+//
+// It reports whether the image is built, a dry run reports it without building it.
+func (ic *ContainerEngine) buildImageFromContainerfile(ctx context.Context, cwd string, writer io.Writer, image string, options entities.PlayKubeOptions) (*libimage.Image, bool, error) {
+	buildFile, err := getBuildFile(playLogger(options), image, cwd)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	existsLocally, err := ic.Libpod.LibimageRuntime().Exists(image)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	if (len(buildFile) > 0) && ((!existsLocally && options.Build != types.OptionalBoolFalse) || (options.Build == types.OptionalBoolTrue)) {
+		if options.DryRun {
+			return nil, true, nil
+		}
 		buildOpts := new(buildahDefine.BuildOptions)
 		commonOpts := new(buildahDefine.CommonBuildOptions)
 		buildOpts.ConfigureNetwork = buildahDefine.NetworkDefault
 		isolation, err := bparse.IsolationOption("")
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		buildOpts.Isolation = isolation
 		buildOpts.CommonBuildOpts = commonOpts
@@ -1235,31 +1959,41 @@ func (ic *ContainerEngine) buildImageFromContainerfile(ctx context.Context, cwd
 		buildOpts.Output = image
 		buildOpts.ContextDirectory = filepath.Dir(buildFile)
 		buildOpts.ReportWriter = writer
+		buildOpts.Args = options.BuildArgs
+		buildOpts.NoCache = options.NoCache
 		if _, _, err := ic.Libpod.Build(ctx, *buildOpts, []string{buildFile}...); err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		builtImage, _, err := ic.Libpod.LibimageRuntime().LookupImage(image, new(libimage.LookupImageOptions))
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		return builtImage, nil
+		return builtImage, true, nil
 	}
-	return nil, nil
+	return nil, false, nil
 }
 
 // pullImageWithPolicy invokes libimage.Pull() to pull an image with the given PullPolicy.
+// The pull policy of the options takes precedence over the one of the container.
 // If the PullPolicy is not set:
 // - use PullPolicyNewer if the image tag is set to "latest" or is not set
 // - use PullPolicyMissing the policy is set to PullPolicyNewer.
-func (ic *ContainerEngine) pullImageWithPolicy(ctx context.Context, writer io.Writer, image string, policy v1.PullPolicy, options entities.PlayKubeOptions) (*libimage.Image, error) {
+// With SkipExisting, an image found locally is used whatever the policy.
+// It reports whether the image was pulled rather than found locally. A dry run
+// reports the image as pulled unless the policy uses the local image, without
+// pulling it.
+func (ic *ContainerEngine) pullImageWithPolicy(ctx context.Context, writer io.Writer, image string, policy v1.PullPolicy, options entities.PlayKubeOptions) (*libimage.Image, bool, error) {
 	pullPolicy := config.PullPolicyMissing
+	if options.PullPolicy != "" {
+		policy = v1.PullPolicy(options.PullPolicy)
+	}
 	if len(policy) > 0 {
 		// Make sure to lower the strings since K8s pull policy
 		// may be capitalized (see bugzilla.redhat.com/show_bug.cgi?id=1985905).
 		rawPolicy := string(policy)
 		parsedPolicy, err := config.ParsePullPolicy(strings.ToLower(rawPolicy))
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		pullPolicy = parsedPolicy
 	} else {
@@ -1280,42 +2014,157 @@ func (ic *ContainerEngine) pullImageWithPolicy(ctx context.Context, writer io.Wr
 	pullOptions.Username = options.Username
 	pullOptions.Password = options.Password
 	pullOptions.InsecureSkipTLSVerify = options.SkipTLSVerify
+	pullOptions.MaxRetries = options.Retry
+	if options.RetryDelay != "" {
+		duration, err := time.ParseDuration(options.RetryDelay)
+		if err != nil {
+			return nil, false, err
+		}
+		pullOptions.RetryDelay = &duration
+	}
 
-	pulledImages, err := ic.Libpod.LibimageRuntime().Pull(ctx, image, pullPolicy, pullOptions)
+	// The image is pulled from the registry mirror, if any, under its own
+	// name. It is looked up under the name of the YAML, which it is given
+	// once pulled.
+	pullName := mirrorImage(image, options.RegistryMirror)
+
+	var localID string
+	localImage, _, err := ic.Libpod.LibimageRuntime().LookupImage(image, nil)
+	if err == nil {
+		if options.SkipExisting || (pullName != image && pullPolicy == config.PullPolicyMissing) {
+			return localImage, false, checkAllowedDigest(image, localImage.Digests(), options.AllowedDigests)
+		}
+		localID = localImage.ID()
+	}
+	if options.DryRun {
+		switch {
+		case localID != "" && (pullPolicy == config.PullPolicyMissing || pullPolicy == config.PullPolicyNever):
+			return localImage, false, checkAllowedDigest(image, localImage.Digests(), options.AllowedDigests)
+		case pullPolicy == config.PullPolicyNever:
+			return nil, false, fmt.Errorf("%s: %w", image, storage.ErrImageUnknown)
+		}
+		return nil, true, nil
+	}
+
+	playLogger(options).Debugf("Pulling image %s with policy %s", pullName, pullPolicy)
+	pulledImages, err := ic.Libpod.LibimageRuntime().Pull(ctx, pullName, pullPolicy, pullOptions)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	if err := checkAllowedDigest(image, pulledImages[0].Digests(), options.AllowedDigests); err != nil {
+		return nil, false, err
+	}
+	if pullName != image {
+		name, err := mirroredImageName(image)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := pulledImages[0].Tag(name); err != nil {
+			return nil, false, err
+		}
+	}
+	return pulledImages[0], pulledImages[0].ID() != localID, err
+}
+
+// mirroredImageName returns the name an image pulled from the registry mirror
+// is tagged with, for the containers and volumes to find it by the name image
+// of the YAML.
+func mirroredImageName(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	digested, ok := named.(reference.Digested)
+	if !ok {
+		return named.String(), nil
+	}
+	// Images cannot be tagged by digest, a digested reference is found by
+	// any name of its repository: the tag of the reference is used, or one
+	// derived from the digest not to move another tag of the repository.
+	tag := digested.Digest().Algorithm().String() + "-" + digested.Digest().Encoded()
+	if tagged, ok := named.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+	withTag, err := reference.WithTag(reference.TrimNamed(named), tag)
+	if err != nil {
+		return "", err
+	}
+	return withTag.String(), nil
+}
+
+// unpinnedImages returns the images not referenced by digest.
+func unpinnedImages(images []string) []string {
+	var unpinned []string
+	for _, image := range images {
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			unpinned = append(unpinned, image)
+			continue
+		}
+		if _, ok := named.(reference.Canonical); !ok {
+			unpinned = append(unpinned, image)
+		}
+	}
+	return unpinned
+}
+
+// checkAllowedDigest fails with ErrImageNotAllowed when allowed maps image to a
+// digest that is not one of the digests the image resolved to.
+func checkAllowedDigest(image string, digests []digest.Digest, allowed map[string]string) error {
+	expected, ok := allowed[image]
+	if !ok {
+		return nil
+	}
+	if slices.Contains(digests, digest.Digest(expected)) {
+		return nil
 	}
-	return pulledImages[0], err
+	return fmt.Errorf("%w: %s resolved to %v, expected %s", ErrImageNotAllowed, image, digests, expected)
 }
 
+// imageOrigin tells where the image of a container or volume comes from.
+type imageOrigin int
+
+const (
+	// imageLocal is an image found in the local storage.
+	imageLocal imageOrigin = iota
+	// imagePulled is an image pulled from its registry.
+	imagePulled
+	// imageBuilt is an image built from the context directory.
+	imageBuilt
+)
+
 // buildOrPullImage builds the image if a Containerfile is present in a directory
 // with the name of the image. It pulls the image otherwise. It returns the image
-// details.
-func (ic *ContainerEngine) buildOrPullImage(ctx context.Context, cwd string, writer io.Writer, image string, policy v1.PullPolicy, options entities.PlayKubeOptions) (*libimage.Image, error) {
-	buildImage, err := ic.buildImageFromContainerfile(ctx, cwd, writer, image, options)
+// details and where the image comes from.
+func (ic *ContainerEngine) buildOrPullImage(ctx context.Context, cwd string, writer io.Writer, image string, policy v1.PullPolicy, options entities.PlayKubeOptions) (*libimage.Image, imageOrigin, error) {
+	buildImage, built, err := ic.buildImageFromContainerfile(ctx, cwd, writer, image, options)
 	if err != nil {
-		return nil, err
+		return nil, imageLocal, err
 	}
-	if buildImage != nil {
-		return buildImage, nil
-	} else {
-		return ic.pullImageWithPolicy(ctx, writer, image, policy, options)
+	if built {
+		return buildImage, imageBuilt, nil
+	}
+	pulledImage, pulled, err := ic.pullImageWithPolicy(ctx, writer, image, policy, options)
+	if err != nil || !pulled {
+		return pulledImage, imageLocal, err
 	}
+	return pulledImage, imagePulled, nil
 }
 
 // getImageAndLabelInfo returns the image information and how the image should be pulled plus as well as labels to be used for the container in the pod.
+// It also reports where the image comes from.
 // Moved this to a separate function so that it can be used for both init and regular containers when playing a kube yaml.
-func (ic *ContainerEngine) getImageAndLabelInfo(ctx context.Context, cwd string, annotations map[string]string, writer io.Writer, container v1.Container, options entities.PlayKubeOptions) (*libimage.Image, map[string]string, error) {
+func (ic *ContainerEngine) getImageAndLabelInfo(ctx context.Context, cwd string, annotations map[string]string, writer io.Writer, container v1.Container, options entities.PlayKubeOptions) (*libimage.Image, map[string]string, imageOrigin, error) {
 	// Contains all labels obtained from kube
 	labels := make(map[string]string)
 
 	if len(container.Image) == 0 {
-		return nil, labels, nil
+		return nil, labels, imageLocal, nil
 	}
 
-	pulledImage, err := ic.buildOrPullImage(ctx, cwd, writer, container.Image, container.ImagePullPolicy, options)
+	pulledImage, origin, err := ic.buildOrPullImage(ctx, cwd, writer, container.Image, container.ImagePullPolicy, options)
 	if err != nil {
-		return nil, labels, err
+		return nil, labels, imageLocal, err
 	}
 
 	// Handle kube annotations
@@ -1339,11 +2188,12 @@ func (ic *ContainerEngine) getImageAndLabelInfo(ctx context.Context, cwd string,
 	setLabel(define.AutoUpdateLabel)
 	setLabel(define.AutoUpdateAuthfileLabel)
 
-	return pulledImage, labels, nil
+	return pulledImage, labels, origin, nil
 }
 
 // playKubePVC creates a podman volume from a kube persistent volume claim.
-func (ic *ContainerEngine) playKubePVC(ctx context.Context, mountLabel string, pvcYAML *v1.PersistentVolumeClaim) (*entities.PlayKubeReport, error) {
+// A dry run only validates the claim and reports the volume.
+func (ic *ContainerEngine) playKubePVC(ctx context.Context, mountLabel string, pvcYAML *v1.PersistentVolumeClaim, options entities.PlayKubeOptions) (*entities.PlayKubeReport, error) {
 	var report entities.PlayKubeReport
 	opts := make(map[string]string)
 
@@ -1414,6 +2264,11 @@ func (ic *ContainerEngine) playKubePVC(ctx context.Context, mountLabel string, p
 		defer tarFile.Close()
 	}
 
+	if options.DryRun {
+		report.Volumes = append(report.Volumes, entitiesTypes.PlayKubeVolume{Name: name})
+		return &report, nil
+	}
+
 	// Create volume.
 	vol, err := ic.Libpod.NewVolume(ctx, volOptions...)
 	if err != nil {
@@ -1425,7 +2280,7 @@ func (ic *ContainerEngine) playKubePVC(ctx context.Context, mountLabel string, p
 		if err != nil {
 			// Remove the volume to avoid partial success
 			if rmErr := ic.Libpod.RemoveVolume(ctx, vol, true, nil); rmErr != nil {
-				logrus.Debug(rmErr)
+				playLogger(options).Debug(rmErr)
 			}
 			return nil, err
 		}
@@ -1646,26 +2501,26 @@ func imageNamePrefix(imageName string) string {
 	return prefix
 }
 
-func getBuildFile(imageName string, cwd string) (string, error) {
+func getBuildFile(logger *logrus.Entry, imageName string, cwd string) (string, error) {
 	buildDirName := imageNamePrefix(imageName)
 	containerfilePath := filepath.Join(cwd, buildDirName, "Containerfile")
 	dockerfilePath := filepath.Join(cwd, buildDirName, "Dockerfile")
 
 	err := fileutils.Exists(containerfilePath)
 	if err == nil {
-		logrus.Debugf("Building %s with %s", imageName, containerfilePath)
+		logger.Debugf("Building %s with %s", imageName, containerfilePath)
 		return containerfilePath, nil
 	}
 	// If the error is not because the file does not exist, take
 	// a mulligan and try Dockerfile.  If that also fails, return that
 	// error
 	if !errors.Is(err, os.ErrNotExist) {
-		logrus.Error(err.Error())
+		logger.Error(err.Error())
 	}
 
 	err = fileutils.Exists(dockerfilePath)
 	if err == nil {
-		logrus.Debugf("Building %s with %s", imageName, dockerfilePath)
+		logger.Debugf("Building %s with %s", imageName, dockerfilePath)
 		return dockerfilePath, nil
 	}
 	// Strike two
@@ -1675,6 +2530,251 @@ func getBuildFile(imageName string, cwd string) (string, error) {
 	return "", err
 }
 
+// checkBuildContext verifies that the context directory images are built
+// from is a directory. When a build is requested, it returns a warning if no
+// container of documentList has a Containerfile or Dockerfile to build with.
+func checkBuildContext(documentList [][]byte, options entities.PlayKubeOptions) ([]string, error) {
+	cwd := options.ContextDir
+	if cwd != "" {
+		info, err := os.Stat(cwd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid context directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("invalid context directory: %s is not a directory", cwd)
+		}
+	}
+	if options.Build != types.OptionalBoolTrue {
+		return nil, nil
+	}
+	if cwd == "" {
+		var err error
+		cwd, err = os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	images, err := kubeContainerImages(documentList)
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		buildFile, err := getBuildFile(playLogger(options), image, cwd)
+		if err != nil {
+			return nil, err
+		}
+		if buildFile != "" {
+			return nil, nil
+		}
+	}
+	return []string{fmt.Sprintf("build requested but no container image has a Containerfile or Dockerfile in %s", cwd)}, nil
+}
+
+// countKubePods returns the number of pods the documents describe, every
+// workload kind creating a single pod.
+func countKubePods(documentList [][]byte) (int, error) {
+	numPods := 0
+	for _, document := range documentList {
+		kind, err := getKubeKind(document)
+		if err != nil {
+			return 0, err
+		}
+		switch kind {
+		case "Pod", "DaemonSet", "Deployment", "Job":
+			numPods++
+		}
+	}
+	return numPods, nil
+}
+
+// kubeContainers returns the init and regular containers of the Pod,
+// DaemonSet, Deployment and Job documents of documentList.
+func kubeContainers(documentList [][]byte) ([]v1.Container, error) {
+	var containers []v1.Container
+	for _, document := range documentList {
+		kind, err := getKubeKind(document)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "Pod", "DaemonSet", "Deployment", "Job":
+		default:
+			continue
+		}
+		// Pods hold their spec directly, the workloads in their template.
+		var workload struct {
+			Spec struct {
+				v1.PodSpec
+				Template struct {
+					Spec v1.PodSpec `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		}
+		if err := yaml.Unmarshal(document, &workload); err != nil {
+			return nil, fmt.Errorf("unable to read YAML as Kube %s: %w", kind, err)
+		}
+		for _, spec := range []v1.PodSpec{workload.Spec.PodSpec, workload.Spec.Template.Spec} {
+			containers = append(containers, slices.Concat(spec.InitContainers, spec.Containers)...)
+		}
+	}
+	return containers, nil
+}
+
+// kubeContainerImages returns the images of the containers of the Pod,
+// DaemonSet, Deployment and Job documents of documentList, without
+// duplicates.
+func kubeContainerImages(documentList [][]byte) ([]string, error) {
+	containers, err := kubeContainers(documentList)
+	if err != nil {
+		return nil, err
+	}
+	var images []string
+	for _, container := range containers {
+		if container.Image != "" && !slices.Contains(images, container.Image) {
+			images = append(images, container.Image)
+		}
+	}
+	return images, nil
+}
+
+// validateKubeDocuments strictly unmarshals each document of documentList in
+// the type of its kind, failing on the first unknown field or mistyped value.
+// Documents of unsupported kinds are not validated.
+func validateKubeDocuments(documentList [][]byte) error {
+	for i, document := range documentList {
+		kind, err := getKubeKind(document)
+		if err != nil {
+			return fmt.Errorf("%w: document %d: %v", ErrInvalidKubeYAML, i+1, err)
+		}
+		var obj any
+		switch kind {
+		case "Pod":
+			obj = &v1.Pod{}
+		case "DaemonSet":
+			obj = &v1apps.DaemonSet{}
+		case "Deployment":
+			obj = &v1apps.Deployment{}
+		case "Job":
+			obj = &v1.Job{}
+		case "PersistentVolumeClaim":
+			obj = &v1.PersistentVolumeClaim{}
+		case "ConfigMap":
+			obj = &v1.ConfigMap{}
+		case "Secret":
+			obj = &v1.Secret{}
+		default:
+			continue
+		}
+		if err := yaml.UnmarshalStrict(document, obj); err != nil {
+			return fmt.Errorf("%w: document %d (%s): %v", ErrInvalidKubeYAML, i+1, kind, err)
+		}
+	}
+	return nil
+}
+
+// privilegedContainers returns the names of the containers of documentList
+// whose security context asks for privileges.
+func privilegedContainers(documentList [][]byte) ([]string, error) {
+	containers, err := kubeContainers(documentList)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, container := range containers {
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			names = append(names, container.Name)
+		}
+	}
+	return names, nil
+}
+
+// buildImages builds the images of documentList that have a Containerfile or
+// Dockerfile in the context directory, running up to
+// options.BuildParallelism builds at a time. Images built from the same file
+// are built one after the other.
+func (ic *ContainerEngine) buildImages(ctx context.Context, documentList [][]byte, options entities.PlayKubeOptions) ([]entitiesTypes.PlayKubeBuild, error) {
+	cwd := options.ContextDir
+	if cwd == "" {
+		var err error
+		cwd, err = os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+	}
+	images, err := kubeContainerImages(documentList)
+	if err != nil {
+		return nil, err
+	}
+	var buildFiles []string
+	buildFileImages := make(map[string][]string)
+	for _, image := range images {
+		buildFile, err := getBuildFile(playLogger(options), image, cwd)
+		if err != nil {
+			return nil, err
+		}
+		if buildFile == "" {
+			continue
+		}
+		if _, ok := buildFileImages[buildFile]; !ok {
+			buildFiles = append(buildFiles, buildFile)
+		}
+		buildFileImages[buildFile] = append(buildFileImages[buildFile], image)
+	}
+
+	var writer io.Writer
+	switch {
+	case options.Writer != nil:
+		writer = &lockedWriter{w: options.Writer}
+	case !options.Quiet:
+		writer = &lockedWriter{w: os.Stderr}
+	}
+
+	builds := make([][]entitiesTypes.PlayKubeBuild, len(buildFiles))
+	var group errgroup.Group
+	group.SetLimit(int(options.BuildParallelism))
+	for i, buildFile := range buildFiles {
+		group.Go(func() error {
+			for _, image := range buildFileImages[buildFile] {
+				_, built, err := ic.buildImageFromContainerfile(ctx, cwd, writer, image, options)
+				if err != nil {
+					builds[i] = append(builds[i], entitiesTypes.PlayKubeBuild{Image: image, Error: err.Error()})
+					return fmt.Errorf("building image %s: %w", image, err)
+				}
+				if built {
+					builds[i] = append(builds[i], entitiesTypes.PlayKubeBuild{Image: image})
+				}
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	return slices.Concat(builds...), err
+}
+
+// namespacedPods returns the names the pods podNames have when played in
+// namespace. Pods of that name that were not played in namespace are left in
+// place and recorded as skipped in reports.
+func (ic *ContainerEngine) namespacedPods(podNames []string, namespace string, reports *entities.PlayKubeReport) ([]string, error) {
+	names := make([]string, 0, len(podNames))
+	for _, name := range podNames {
+		name = namespacedPodName(namespace, name)
+		pod, err := ic.Libpod.LookupPod(name)
+		if err != nil {
+			if errors.Is(err, define.ErrNoSuchPod) {
+				continue
+			}
+			return nil, err
+		}
+		if pod.Labels()[kubeNamespaceLabel] != namespace {
+			reports.Skipped = append(reports.Skipped, "Pod/"+name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
 func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, options entities.PlayKubeDownOptions) (*entities.PlayKubeReport, error) {
 	var (
 		podNames    []string
@@ -1701,6 +2801,20 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 		return nil, fmt.Errorf("unable to sort kube kinds: %w", err)
 	}
 
+	selector, err := parseLabelSelector(options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	// skip reports whether the document is left in place because the labels
+	// of its pods do not match the selector.
+	skip := func(kind, name string, labels map[string]string) bool {
+		if selector.matches(labels) {
+			return false
+		}
+		reports.Skipped = append(reports.Skipped, kind+"/"+name)
+		return true
+	}
+
 	for _, document := range documentList {
 		kind, err := getKubeKind(document)
 		if err != nil {
@@ -1713,6 +2827,9 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 			if err := yaml.Unmarshal(document, &podYAML); err != nil {
 				return nil, fmt.Errorf("unable to read YAML as Kube Pod: %w", err)
 			}
+			if skip(kind, podYAML.Name, podYAML.Labels) {
+				continue
+			}
 			podNames = append(podNames, podYAML.ObjectMeta.Name)
 
 			for _, vol := range podYAML.Spec.Volumes {
@@ -1731,6 +2848,9 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 			if err := yaml.Unmarshal(document, &daemonSetYAML); err != nil {
 				return nil, fmt.Errorf("unable to read YAML as Kube DaemonSet: %w", err)
 			}
+			if skip(kind, daemonSetYAML.Name, daemonSetYAML.Spec.Template.Labels) {
+				continue
+			}
 
 			podName := fmt.Sprintf("%s-pod", daemonSetYAML.Name)
 			podNames = append(podNames, podName)
@@ -1740,6 +2860,9 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 			if err := yaml.Unmarshal(document, &deploymentYAML); err != nil {
 				return nil, fmt.Errorf("unable to read YAML as Kube Deployment: %w", err)
 			}
+			if skip(kind, deploymentYAML.Name, deploymentYAML.Spec.Template.Labels) {
+				continue
+			}
 			var numReplicas int32 = 1
 			deploymentName := deploymentYAML.ObjectMeta.Name
 			if deploymentYAML.Spec.Replicas != nil {
@@ -1756,6 +2879,9 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 			if err := yaml.Unmarshal(document, &jobYAML); err != nil {
 				return nil, fmt.Errorf("unable to read YAML as Kube Job: %w", err)
 			}
+			if skip(kind, jobYAML.Name, jobYAML.Spec.Template.Labels) {
+				continue
+			}
 			jobName := jobYAML.ObjectMeta.Name
 			podName := fmt.Sprintf("%s-pod", jobName)
 			podNames = append(podNames, podName)
@@ -1764,18 +2890,40 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 			if err := yaml.Unmarshal(document, &pvcYAML); err != nil {
 				return nil, fmt.Errorf("unable to read YAML as Kube PersistentVolumeClaim: %w", err)
 			}
+			if skip(kind, pvcYAML.Name, pvcYAML.Labels) {
+				continue
+			}
 			volumeNames = append(volumeNames, pvcYAML.Name)
 		case "Secret":
 			var secret v1.Secret
 			if err := yaml.Unmarshal(document, &secret); err != nil {
 				return nil, fmt.Errorf("unable to read YAML as Kube Secret: %w", err)
 			}
+			if skip(kind, secret.Name, secret.Labels) {
+				continue
+			}
 			secretNames = append(secretNames, secret.Name)
 		default:
 			continue
 		}
 	}
 
+	if options.Namespace != "" {
+		podNames, err = ic.namespacedPods(podNames, options.Namespace, reports)
+		if err != nil {
+			return nil, err
+		}
+		// Volumes and secrets are not created per namespace, other
+		// namespaces may still use them.
+		for _, name := range secretNames {
+			reports.Skipped = append(reports.Skipped, "Secret/"+name)
+		}
+		for _, name := range volumeNames {
+			reports.Skipped = append(reports.Skipped, "Volume/"+name)
+		}
+		secretNames, volumeNames = nil, nil
+	}
+
 	// Get the service containers associated with the pods if any
 	serviceCtrIDs := []string{}
 	for _, name := range podNames {
@@ -1796,32 +2944,88 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 		serviceCtrIDs = append(serviceCtrIDs, ctr.ID())
 	}
 
-	// Add the reports
+	stopTimeout := -1
+	if options.Timeout > 0 {
+		stopTimeout = int(options.Timeout)
+	}
+
+	// Add the reports. Resources that cannot be removed are recorded in
+	// the report, an error is only returned when none could be removed.
+	var downErrs []error
+	addResult := func(kind, name string, err error) {
+		result := entities.PlayKubeDownResource{Kind: kind, Name: name}
+		if err != nil {
+			result.Error = err.Error()
+			downErrs = append(downErrs, fmt.Errorf("removing %s %s: %w", strings.ToLower(kind), name, err))
+		}
+		reports.Resources = append(reports.Resources, result)
+	}
+
+	// Stop failures are reported in StopReport, the pods are force
+	// removed anyway.
+	podNamesByID := make(map[string]string)
 	reports.StopReport, err = ic.PodStop(ctx, podNames, entities.PodStopOptions{
 		Ignore:  true,
-		Timeout: -1,
+		Timeout: stopTimeout,
 	})
 	if err != nil {
-		return nil, err
+		logrus.Errorf("Stopping pods: %v", err)
+	}
+	for _, stopped := range reports.StopReport {
+		podNamesByID[stopped.Id] = stopped.RawInput
 	}
 
 	reports.RmReport, err = ic.PodRm(ctx, podNames, entities.PodRmOptions{Ignore: true, Force: true})
 	if err != nil {
-		return nil, err
+		for _, name := range podNames {
+			addResult("Pod", name, err)
+		}
+	}
+	for _, removed := range reports.RmReport {
+		name, ok := podNamesByID[removed.Id]
+		if !ok {
+			name = removed.Id
+		}
+		addResult("Pod", name, removed.Err)
 	}
 
 	reports.SecretRmReport, err = ic.SecretRm(ctx, secretNames, entities.SecretRmOptions{Ignore: true})
 	if err != nil {
-		return nil, err
+		for _, name := range secretNames {
+			addResult("Secret", name, err)
+		}
+	}
+	for _, removed := range reports.SecretRmReport {
+		addResult("Secret", removed.ID, removed.Err)
 	}
 
-	if options.Force {
+	switch {
+	case options.KeepVolumes:
+		for _, name := range volumeNames {
+			exists, err := ic.Libpod.HasVolume(name)
+			if err != nil {
+				return nil, err
+			}
+			if exists && !slices.Contains(reports.KeptVolumes, name) {
+				reports.KeptVolumes = append(reports.KeptVolumes, name)
+			}
+		}
+	case options.Force:
 		reports.VolumeRmReport, err = ic.VolumeRm(ctx, volumeNames, entities.VolumeRmOptions{Ignore: true})
 		if err != nil {
-			return nil, err
+			for _, name := range volumeNames {
+				addResult("Volume", name, err)
+			}
+		}
+		for _, removed := range reports.VolumeRmReport {
+			addResult("Volume", removed.Id, removed.Err)
 		}
 	}
 
+	if len(reports.Resources) > 0 && len(downErrs) == len(reports.Resources) {
+		return nil, errors.Join(downErrs...)
+	}
+
 	// Remove the service container to ensure it is removed before we return for the remote case
 	// Needed for the clean up with podman kube play --wait in the remote case
 	if len(serviceCtrIDs) > 0 {
@@ -1837,21 +3041,26 @@ func (ic *ContainerEngine) PlayKubeDown(ctx context.Context, body io.Reader, opt
 }
 
 // playKubeSecret allows users to create and store a kubernetes secret as a podman secret
-func (ic *ContainerEngine) playKubeSecret(secret *v1.Secret) (*entities.SecretCreateReport, error) {
-	r := &entities.SecretCreateReport{}
-
+func (ic *ContainerEngine) playKubeSecret(secret *v1.Secret, dryRun bool) (*entities.SecretCreateReport, error) {
 	// Create the secret manager before hand
 	secretsManager, err := ic.Libpod.SecretsManager()
 	if err != nil {
 		return nil, err
 	}
+	return storeKubeSecret(secretsManager, ic.Libpod.GetSecretsStorageDir(), secret, dryRun)
+}
+
+// storeKubeSecret stores the kubernetes secret in the secrets manager,
+// replacing a mutable secret of the same name. A dry run only checks that the
+// secret can be stored and reports it without an ID.
+func storeKubeSecret(secretsManager *secrets.SecretsManager, secretsPath string, secret *v1.Secret, dryRun bool) (*entities.SecretCreateReport, error) {
+	r := &entities.SecretCreateReport{}
 
 	data, err := yaml.Marshal(secret)
 	if err != nil {
 		return nil, err
 	}
 
-	secretsPath := ic.Libpod.GetSecretsStorageDir()
 	opts := make(map[string]string)
 	opts["path"] = filepath.Join(secretsPath, "filedriver")
 	// maybe k8sName(data)...
@@ -1865,6 +3074,11 @@ func (ic *ContainerEngine) playKubeSecret(secret *v1.Secret) (*entities.SecretCr
 				return nil, fmt.Errorf("cannot remove colliding secret as it is set to immutable")
 			}
 		}
+	}
+	if dryRun {
+		return r, nil
+	}
+	if err == nil {
 		_, err = secretsManager.Delete(s.Name)
 		if err != nil {
 			return nil, err
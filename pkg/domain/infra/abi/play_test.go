@@ -4,11 +4,23 @@ package abi
 
 import (
 	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/containers/podman/v5/libpod/define"
+	"github.com/containers/podman/v5/pkg/domain/entities"
+	entitiesTypes "github.com/containers/podman/v5/pkg/domain/entities/types"
 	v1 "github.com/containers/podman/v5/pkg/k8s.io/api/core/v1"
+	"github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/api/resource"
 	v12 "github.com/containers/podman/v5/pkg/k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.podman.io/common/pkg/secrets"
+	"go.podman.io/image/v5/types"
 )
 
 func TestReadConfigMapFromFile(t *testing.T) {
@@ -278,3 +290,424 @@ items:
 		})
 	}
 }
+
+func TestCapResources(t *testing.T) {
+	ceilings, err := resourceCeilings(entities.PlayKubeOptions{CPULimit: "500m", MemoryLimit: "512Mi"})
+	assert.NoError(t, err)
+
+	unset := v1.Container{}
+	capResources(&unset, ceilings)
+	assert.Equal(t, "500m", unset.Resources.Limits.Cpu().String())
+	assert.Equal(t, "512Mi", unset.Resources.Limits.Memory().String())
+
+	higher := v1.Container{Resources: v1.ResourceRequirements{
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("1Gi")},
+		Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+	}}
+	capResources(&higher, ceilings)
+	assert.Equal(t, "500m", higher.Resources.Limits.Cpu().String())
+	assert.Equal(t, "512Mi", higher.Resources.Limits.Memory().String())
+	assert.Equal(t, "512Mi", higher.Resources.Requests.Memory().String())
+
+	lower := v1.Container{Resources: v1.ResourceRequirements{
+		Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("64Mi")},
+	}}
+	capResources(&lower, ceilings)
+	assert.Equal(t, "100m", lower.Resources.Limits.Cpu().String())
+	assert.Equal(t, "64Mi", lower.Resources.Limits.Memory().String())
+
+	_, err = resourceCeilings(entities.PlayKubeOptions{MemoryLimit: "lots"})
+	assert.ErrorContains(t, err, `invalid memory limit "lots"`)
+}
+
+func TestAddEnv(t *testing.T) {
+	container := v1.Container{Env: []v1.EnvVar{{Name: "LEVEL", Value: "debug"}}}
+	addEnv(&container, map[string]string{"LEVEL": "info", "REGION": "eu", "API": "https://api"})
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "LEVEL", Value: "debug"},
+		{Name: "API", Value: "https://api"},
+		{Name: "REGION", Value: "eu"},
+	}, container.Env)
+}
+
+func TestMirrorImage(t *testing.T) {
+	digest := strings.Repeat("a", 64)
+	for image, expected := range map[string]string{
+		"docker.io/library/nginx:1.25":          "mirror.example.com:5000/library/nginx:1.25",
+		"docker.io/nginx":                       "mirror.example.com:5000/library/nginx",
+		"quay.io/podman/hello@sha256:" + digest: "mirror.example.com:5000/podman/hello@sha256:" + digest,
+		"localhost/app:v1":                      "localhost/app:v1",
+		"localhost:5000/app:v1":                 "localhost:5000/app:v1",
+		"nginx:1.25":                            "nginx:1.25",
+		"library/nginx":                         "library/nginx",
+	} {
+		assert.Equal(t, expected, mirrorImage(image, "mirror.example.com:5000"), image)
+	}
+	assert.Equal(t, "mirror.example.com/dockerhub/library/nginx:1.25", mirrorImage("docker.io/library/nginx:1.25", "mirror.example.com/dockerhub"))
+	assert.Equal(t, "docker.io/library/nginx:1.25", mirrorImage("docker.io/library/nginx:1.25", ""))
+}
+
+func TestMirroredImageName(t *testing.T) {
+	digest := strings.Repeat("a", 64)
+	for image, expected := range map[string]string{
+		"docker.io/library/nginx:1.25":                 "docker.io/library/nginx:1.25",
+		"quay.io/podman/hello":                         "quay.io/podman/hello",
+		"quay.io/podman/hello@sha256:" + digest:        "quay.io/podman/hello:sha256-" + digest,
+		"quay.io/podman/hello:latest@sha256:" + digest: "quay.io/podman/hello:latest",
+	} {
+		name, err := mirroredImageName(image)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, name, image)
+	}
+}
+
+func TestMergeSysctls(t *testing.T) {
+	merged, err := mergeSysctls([]string{"net.core.somaxconn=1024", "kernel.shmmax=65536"}, []string{"net.core.somaxconn=4096", "fs.mqueue.msg_max=64"}, v1.PodSpec{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fs.mqueue.msg_max=64", "kernel.shmmax=65536", "net.core.somaxconn=4096"}, merged)
+
+	_, err = mergeSysctls(nil, []string{"net.core.somaxconn=4096"}, v1.PodSpec{HostNetwork: true})
+	assert.ErrorIs(t, err, ErrSysctlConflict)
+	assert.ErrorContains(t, err, "net.core.somaxconn cannot be set on a pod using the host network")
+
+	_, err = mergeSysctls(nil, []string{"fs.mqueue.msg_max=64"}, v1.PodSpec{HostIPC: true})
+	assert.ErrorIs(t, err, ErrSysctlConflict)
+
+	merged, err = mergeSysctls(nil, []string{"kernel.shmmax=65536"}, v1.PodSpec{HostNetwork: true, HostIPC: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kernel.shmmax=65536"}, merged)
+}
+
+func TestCheckBuildContext(t *testing.T) {
+	contextDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(contextDir, "app"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(contextDir, "app", "Containerfile"), []byte("FROM scratch\n"), 0o644))
+	notDir := filepath.Join(contextDir, "app", "Containerfile")
+
+	pod := []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+  - name: ctr
+    image: quay.io/example/other
+`)
+	deployment := []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: ctr
+        image: localhost/app:latest
+`)
+
+	build := entities.PlayKubeOptions{ContextDir: contextDir, Build: types.OptionalBoolTrue}
+	warnings, err := checkBuildContext([][]byte{pod, deployment}, build)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = checkBuildContext([][]byte{pod}, build)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	warnings, err = checkBuildContext([][]byte{pod}, entities.PlayKubeOptions{ContextDir: contextDir})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	_, err = checkBuildContext(nil, entities.PlayKubeOptions{ContextDir: filepath.Join(contextDir, "missing")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = checkBuildContext(nil, entities.PlayKubeOptions{ContextDir: notDir})
+	assert.ErrorContains(t, err, "is not a directory")
+}
+
+func TestKubeContainerImages(t *testing.T) {
+	pod := []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  initContainers:
+  - name: init
+    image: localhost/init
+  containers:
+  - name: ctr
+    image: localhost/app
+`)
+	job := []byte(`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: job
+spec:
+  template:
+    spec:
+      containers:
+      - name: ctr
+        image: localhost/app
+      - name: other
+        image: localhost/other
+`)
+	configMap := []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	images, err := kubeContainerImages([][]byte{pod, configMap, job})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost/init", "localhost/app", "localhost/other"}, images)
+}
+
+func TestKubePodDigest(t *testing.T) {
+	newPod := func(image string) *v1.PodTemplateSpec {
+		return &v1.PodTemplateSpec{
+			ObjectMeta: v12.ObjectMeta{Name: "pod", Labels: map[string]string{"b": "2", "a": "1"}},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "ctr", Image: image}}},
+		}
+	}
+
+	first, err := kubePodDigest(newPod("alpine"), map[string]string{"x": "1", "y": "2"}, entities.PlayKubeOptions{})
+	assert.NoError(t, err)
+	second, err := kubePodDigest(newPod("alpine"), map[string]string{"y": "2", "x": "1"}, entities.PlayKubeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	changed, err := kubePodDigest(newPod("busybox"), map[string]string{"x": "1", "y": "2"}, entities.PlayKubeOptions{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+
+	annotated, err := kubePodDigest(newPod("alpine"), map[string]string{"x": "1"}, entities.PlayKubeOptions{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, annotated)
+
+	for _, options := range []entities.PlayKubeOptions{
+		{AddHost: []string{"host:10.0.0.1"}},
+		{DNSServers: []net.IP{net.ParseIP("10.0.0.53")}},
+		{Sysctl: []string{"net.ipv4.ip_forward=1"}},
+		{PublishPorts: []string{"8080:80"}},
+		{Networks: []string{"other"}},
+	} {
+		optioned, err := kubePodDigest(newPod("alpine"), map[string]string{"x": "1", "y": "2"}, options)
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, optioned)
+	}
+}
+
+func TestCountKubePods(t *testing.T) {
+	documents := [][]byte{
+		[]byte("apiVersion: v1\nkind: Pod\n"),
+		[]byte("apiVersion: v1\nkind: ConfigMap\n"),
+		[]byte("apiVersion: apps/v1\nkind: Deployment\n"),
+		[]byte("apiVersion: v1\nkind: PersistentVolumeClaim\n"),
+	}
+	numPods, err := countKubePods(documents)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, numPods)
+
+	numPods, err = countKubePods(documents[1:2])
+	assert.NoError(t, err)
+	assert.Zero(t, numPods)
+}
+
+func TestEffectiveTLSVerify(t *testing.T) {
+	registriesConf := filepath.Join(t.TempDir(), "registries.conf")
+	err := os.WriteFile(registriesConf, []byte(`
+[[registry]]
+location = "insecure.example.com"
+insecure = true
+`), 0o644)
+	assert.NoError(t, err)
+	sys := &types.SystemContext{SystemRegistriesConfPath: registriesConf, SystemRegistriesConfDirPath: t.TempDir()}
+
+	assert.False(t, effectiveTLSVerify(types.OptionalBoolTrue, nil, sys))
+	assert.True(t, effectiveTLSVerify(types.OptionalBoolFalse, []string{"insecure.example.com/app"}, sys))
+	assert.True(t, effectiveTLSVerify(types.OptionalBoolUndefined, []string{"quay.io/podman/hello"}, sys))
+	assert.False(t, effectiveTLSVerify(types.OptionalBoolUndefined, []string{"quay.io/podman/hello", "insecure.example.com/app:1"}, sys))
+}
+
+func TestPlayKubeNetworks(t *testing.T) {
+	networks := map[string]*define.InspectAdditionalNetwork{
+		"podman": {
+			InspectBasicNetworkConfig: define.InspectBasicNetworkConfig{
+				IPAddress:         "10.88.0.2",
+				GlobalIPv6Address: "fd00::2",
+			},
+		},
+		"backend": {
+			InspectBasicNetworkConfig: define.InspectBasicNetworkConfig{
+				IPAddress:            "10.89.0.2",
+				SecondaryIPAddresses: []define.Address{{Addr: "10.89.0.3", PrefixLength: 24}},
+			},
+		},
+		"pasta": {},
+	}
+	assert.Equal(t, []entities.PlayKubePodNetwork{
+		{Name: "backend", IPs: []string{"10.89.0.2", "10.89.0.3"}},
+		{Name: "podman", IPs: []string{"10.88.0.2", "fd00::2"}},
+	}, playKubeNetworks(networks))
+
+	assert.Nil(t, playKubeNetworks(nil))
+}
+
+func TestValidateKubeDocuments(t *testing.T) {
+	valid := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: quay.io/libpod/alpine
+`)
+	service := []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  unknownField: true
+`)
+	assert.NoError(t, validateKubeDocuments([][]byte{valid, service}))
+
+	mistyped := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: "3"
+`)
+	err := validateKubeDocuments([][]byte{valid, mistyped})
+	assert.ErrorIs(t, err, ErrInvalidKubeYAML)
+	assert.ErrorContains(t, err, "document 2 (Deployment)")
+	assert.ErrorContains(t, err, "spec.replicas")
+
+	unknown := []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    imagePolicy: Always
+`)
+	err = validateKubeDocuments([][]byte{unknown})
+	assert.ErrorIs(t, err, ErrInvalidKubeYAML)
+	assert.ErrorContains(t, err, `unknown field "imagePolicy"`)
+}
+
+func TestPrivilegedContainers(t *testing.T) {
+	documents := [][]byte{
+		[]byte(`apiVersion: v1
+kind: Pod
+spec:
+  initContainers:
+  - name: setup
+    securityContext:
+      privileged: true
+  containers:
+  - name: app
+    securityContext:
+      privileged: false
+  - name: plain
+`),
+		[]byte(`apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: agent
+        securityContext:
+          privileged: true
+`),
+		[]byte("apiVersion: v1\nkind: ConfigMap\n"),
+	}
+	names, err := privilegedContainers(documents)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"setup", "agent"}, names)
+
+	names, err = privilegedContainers(documents[2:])
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestUnpinnedImages(t *testing.T) {
+	pinned := "quay.io/podman/hello@sha256:" + strings.Repeat("a", 64)
+	assert.Equal(t, []string{"quay.io/podman/hello:latest", "alpine", "Invalid"},
+		unpinnedImages([]string{pinned, "quay.io/podman/hello:latest", "alpine", "Invalid"}))
+	assert.Empty(t, unpinnedImages([]string{pinned}))
+}
+
+func TestCheckAllowedDigest(t *testing.T) {
+	good := digest.Digest("sha256:" + strings.Repeat("a", 64))
+	other := digest.Digest("sha256:" + strings.Repeat("b", 64))
+	allowed := map[string]string{"quay.io/app:1": good.String()}
+
+	assert.NoError(t, checkAllowedDigest("quay.io/app:1", []digest.Digest{other, good}, allowed))
+	assert.NoError(t, checkAllowedDigest("quay.io/other:1", []digest.Digest{other}, allowed))
+	err := checkAllowedDigest("quay.io/app:1", []digest.Digest{other}, allowed)
+	assert.ErrorIs(t, err, ErrImageNotAllowed)
+	assert.ErrorContains(t, err, "quay.io/app:1")
+}
+
+func TestAppendBuild(t *testing.T) {
+	builds := appendBuild(nil, "localhost/app")
+	builds = appendBuild(builds, "localhost/web")
+	builds = appendBuild(builds, "localhost/app")
+	assert.Equal(t, []entitiesTypes.PlayKubeBuild{{Image: "localhost/app"}, {Image: "localhost/web"}}, builds)
+}
+
+func TestStoreKubeSecretDryRun(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := secrets.NewManager(dir)
+	require.NoError(t, err)
+
+	secret := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{Name: "creds"},
+		Data:       map[string][]byte{"user": []byte("podman")},
+	}
+	r, err := storeKubeSecret(manager, dir, secret, true)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Empty(t, r.ID)
+	_, err = manager.Lookup("creds")
+	assert.ErrorIs(t, err, secrets.ErrNoSuchSecret)
+
+	r, err = storeKubeSecret(manager, dir, secret, false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, r.ID)
+
+	// A dry run leaves a colliding secret in place.
+	dryRunReport, err := storeKubeSecret(manager, dir, secret, true)
+	require.NoError(t, err)
+	assert.Empty(t, dryRunReport.ID)
+	stored, err := manager.Lookup("creds")
+	require.NoError(t, err)
+	assert.Equal(t, r.ID, stored.ID)
+
+	// and still fails on an immutable one.
+	immutable := true
+	secret.Immutable = &immutable
+	_, err = storeKubeSecret(manager, dir, secret, false)
+	require.NoError(t, err)
+	_, err = storeKubeSecret(manager, dir, secret, true)
+	assert.ErrorContains(t, err, "immutable")
+}
+
+func TestCheckReadyState(t *testing.T) {
+	assert.NoError(t, checkReadyState("app", define.ContainerStateRunning, 0))
+	// a container run to completion is ready
+	assert.NoError(t, checkReadyState("job", define.ContainerStateExited, 0))
+	assert.EqualError(t, checkReadyState("job", define.ContainerStateExited, 1), "container job exited with code 1")
+	assert.EqualError(t, checkReadyState("app", define.ContainerStatePaused, 0), "container app is paused")
+}
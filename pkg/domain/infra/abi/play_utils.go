@@ -4,8 +4,10 @@ package abi
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/containers/podman/v5/libpod/define"
 	"golang.org/x/sys/unix"
@@ -53,3 +55,88 @@ func openPathSafely(root, unsafeName string) (*os.File, error) {
 	}
 	return nil, &os.PathError{Op: "openat", Path: unsafeName, Err: err}
 }
+
+// labelRequirement is a single requirement of a labelSelector.
+type labelRequirement struct {
+	key   string
+	value string
+	// op is one of "=", "!=", "exists" or "!exists".
+	op string
+}
+
+// labelSelector is an equality-based Kubernetes label selector, all its
+// requirements must be met for labels to match.
+type labelSelector []labelRequirement
+
+// parseLabelSelector parses the comma separated requirements of selector:
+// key=value, key==value, key!=value, key and !key.
+func parseLabelSelector(selector string) (labelSelector, error) {
+	var s labelSelector
+	for req := range strings.SplitSeq(selector, ",") {
+		req = strings.TrimSpace(req)
+		var r labelRequirement
+		switch {
+		case req == "":
+			continue
+		case strings.Contains(req, "!="):
+			r.key, r.value, _ = strings.Cut(req, "!=")
+			r.op = "!="
+		case strings.Contains(req, "=="):
+			r.key, r.value, _ = strings.Cut(req, "==")
+			r.op = "="
+		case strings.Contains(req, "="):
+			r.key, r.value, _ = strings.Cut(req, "=")
+			r.op = "="
+		case strings.HasPrefix(req, "!"):
+			r.key = strings.TrimPrefix(req, "!")
+			r.op = "!exists"
+		default:
+			r.key = req
+			r.op = "exists"
+		}
+		r.key, r.value = strings.TrimSpace(r.key), strings.TrimSpace(r.value)
+		if r.key == "" || strings.ContainsAny(r.key+r.value, "=!() ") {
+			return nil, fmt.Errorf("invalid label selector requirement %q", req)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// matches reports whether labels meet all the requirements of s.
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, found := labels[r.key]
+		switch r.op {
+		case "=":
+			if !found || value != r.value {
+				return false
+			}
+		case "!=":
+			if found && value == r.value {
+				return false
+			}
+		case "exists":
+			if !found {
+				return false
+			}
+		case "!exists":
+			if found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lockedWriter serializes the writes of concurrent image builds.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
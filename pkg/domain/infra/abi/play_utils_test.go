@@ -38,3 +38,33 @@ func TestGetSdNotifyMode(t *testing.T) {
 		require.Equal(t, test.result, result, "%v", test)
 	}
 }
+
+func TestLabelSelector(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "front"}
+	tests := []struct {
+		selector string
+		matches  bool
+	}{
+		{"", true},
+		{"app=web", true},
+		{"app==web", true},
+		{"app=db", false},
+		{"app=web,tier!=back", true},
+		{"app=web, tier!=front", false},
+		{"tier", true},
+		{"!tier", false},
+		{"env", false},
+		{"!env", true},
+		{"env!=prod", true},
+	}
+	for _, test := range tests {
+		selector, err := parseLabelSelector(test.selector)
+		require.NoError(t, err, test.selector)
+		require.Equal(t, test.matches, selector.matches(labels), test.selector)
+	}
+
+	for _, invalid := range []string{"=web", "app in (web)", "app=!web"} {
+		_, err := parseLabelSelector(invalid)
+		require.Error(t, err, invalid)
+	}
+}
@@ -47,6 +47,17 @@ func (ic *ContainerEngine) GenerateSystemd(_ context.Context, nameOrID string, o
 // Note: Caller is responsible for closing returned Reader
 func (ic *ContainerEngine) GenerateKube(_ context.Context, nameOrIDs []string, opts entities.GenerateKubeOptions) (*entities.GenerateKubeReport, error) {
 	options := new(generate.KubeOptions).WithService(opts.Service).WithType(opts.Type).WithReplicas(opts.Replicas).WithNoTrunc(opts.UseLongAnnotations).WithPodmanOnly(opts.PodmanOnly)
+	options.WithNetworkPolicy(opts.NetworkPolicy).WithSplit(opts.Split)
+	options.WithPVC(opts.PVC)
+	if opts.PVCStorageClass != "" {
+		options.WithPVCStorageClass(opts.PVCStorageClass)
+	}
+	if opts.PVCSize != "" {
+		options.WithPVCSize(opts.PVCSize)
+	}
+	if opts.Format != "" {
+		options.WithFormat(opts.Format)
+	}
 	return generate.Kube(ic.ClientCtx, nameOrIDs, options)
 }
 
@@ -65,6 +76,12 @@ func (ic *ContainerEngine) PlayKube(_ context.Context, body io.Reader, opts enti
 	if opts.Annotations != nil {
 		options.WithAnnotations(opts.Annotations)
 	}
+	if len(opts.AddHost) > 0 {
+		options.WithAddHost(opts.AddHost)
+	}
+	if opts.BuildArgs != nil {
+		options.WithBuildArgs(opts.BuildArgs)
+	}
 	options.WithNoHostname(opts.NoHostname).WithNoHosts(opts.NoHosts).WithUserns(opts.Userns)
 	if s := opts.SkipTLSVerify; s != types.OptionalBoolUndefined {
 		options.WithSkipTLSVerify(s == types.OptionalBoolTrue)
@@ -76,14 +93,68 @@ func (ic *ContainerEngine) PlayKube(_ context.Context, body io.Reader, opts enti
 	options.WithPublishAllPorts(opts.PublishAllPorts)
 	options.WithNoTrunc(opts.UseLongAnnotations)
 	options.WithNoPodPrefix(opts.NoPodPrefix)
+	options.WithDryRun(opts.DryRun)
+	options.WithNamespace(opts.Namespace).WithPullPolicy(opts.PullPolicy)
+	options.WithCPULimit(opts.CPULimit).WithMemoryLimit(opts.MemoryLimit)
+	options.WithNamePrefix(opts.NamePrefix).WithNoCache(opts.NoCache).WithAtomic(opts.Atomic)
+	options.WithSkipExisting(opts.SkipExisting)
+	if opts.DenyPrivileged {
+		options.WithAllowPrivileged(false)
+	}
+	options.WithRequireDigest(opts.RequireDigest).WithWaitReady(opts.WaitReady)
+	if opts.CgroupParent != "" {
+		options.WithCgroupParent(opts.CgroupParent)
+	}
+	if len(opts.Sysctl) > 0 {
+		options.WithSysctl(opts.Sysctl)
+	}
+	if len(opts.DNSServers) > 0 {
+		servers := make([]string, 0, len(opts.DNSServers))
+		for _, server := range opts.DNSServers {
+			servers = append(servers, server.String())
+		}
+		options.WithDNSServer(servers)
+	}
+	if len(opts.DNSSearch) > 0 {
+		options.WithDNSSearch(opts.DNSSearch)
+	}
+	if len(opts.DNSOptions) > 0 {
+		options.WithDNSOption(opts.DNSOptions)
+	}
+	if opts.StrictValidation {
+		options.WithValidate("strict")
+	}
+	if opts.RegistryMirror != "" {
+		options.WithMirror(opts.RegistryMirror)
+	}
+	if len(opts.AllowedDigests) > 0 {
+		options.WithAllowedDigests(opts.AllowedDigests)
+	}
+	options.WithRestart(opts.Restart)
+	if opts.Hostname != "" {
+		options.WithHostname(opts.Hostname)
+	}
+	if opts.BuildParallelism > 0 {
+		options.WithBuildParallelism(opts.BuildParallelism)
+	}
+	if opts.Timeout > 0 {
+		options.WithTimeout(opts.Timeout)
+	}
+	if opts.Retry != nil {
+		options.WithRetry(*opts.Retry)
+	}
+	if opts.RetryDelay != "" {
+		options.WithRetryDelay(opts.RetryDelay)
+	}
 	return play.KubeWithBody(ic.ClientCtx, body, options)
 }
 
 func (ic *ContainerEngine) PlayKubeDown(_ context.Context, body io.Reader, options entities.PlayKubeDownOptions) (*entities.PlayKubeReport, error) {
-	return play.DownWithBody(ic.ClientCtx, body, kube.DownOptions{Force: &options.Force})
+	return play.DownWithBody(ic.ClientCtx, body, kube.DownOptions{Force: &options.Force, Timeout: &options.Timeout, KeepVolumes: &options.KeepVolumes, LabelSelector: &options.LabelSelector, Namespace: &options.Namespace})
 }
 
-func (ic *ContainerEngine) KubeApply(_ context.Context, body io.Reader, opts entities.ApplyOptions) error {
+func (ic *ContainerEngine) KubeApply(_ context.Context, body io.Reader, opts entities.ApplyOptions) (*entities.ApplyReport, error) {
 	options := new(kube.ApplyOptions).WithKubeconfig(opts.Kubeconfig).WithCACertFile(opts.CACertFile).WithNamespace(opts.Namespace)
+	options.WithServerSideApply(opts.ServerSideApply).WithFieldManager(opts.FieldManager)
 	return kube.ApplyWithBody(ic.ClientCtx, body, options)
 }
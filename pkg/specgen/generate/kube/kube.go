@@ -44,7 +44,7 @@ import (
 )
 
 func ToPodOpt(_ context.Context, podName string, p entities.PodCreateOptions, publishAllPorts bool, podYAML *v1.PodTemplateSpec) (entities.PodCreateOptions, error) {
-	p.Net = &entities.NetOptions{NoHosts: p.Net.NoHosts, NoHostname: p.Net.NoHostname}
+	p.Net = &entities.NetOptions{NoHosts: p.Net.NoHosts, NoHostname: p.Net.NoHostname, AddHosts: p.Net.AddHosts}
 
 	p.Name = podName
 	p.Labels = podYAML.ObjectMeta.Labels
@@ -80,13 +80,11 @@ func ToPodOpt(_ context.Context, podName string, p entities.PodCreateOptions, pu
 		if p.Net.NoHosts {
 			return p, errors.New("HostAliases in yaml file will not work with --no-hosts")
 		}
-		hosts := make([]string, 0, len(podYAML.Spec.HostAliases))
 		for _, hostAlias := range podYAML.Spec.HostAliases {
 			for _, host := range hostAlias.Hostnames {
-				hosts = append(hosts, host+":"+hostAlias.IP)
+				p.Net.AddHosts = append(p.Net.AddHosts, host+":"+hostAlias.IP)
 			}
 		}
-		p.Net.AddHosts = hosts
 	}
 	podPorts := getPodPorts(podYAML.Spec.Containers, publishAllPorts)
 	p.Net.PublishPorts = podPorts
@@ -187,6 +185,9 @@ type CtrSpecGenOptions struct {
 	TerminationGracePeriodSeconds *int64
 	// Don't use pod name as prefix in resulting container name.
 	NoPodPrefix bool
+	// NamePrefix is prepended to the container name when NoPodPrefix is set,
+	// the pod name already carries it otherwise.
+	NamePrefix string
 }
 
 func ToSpecGen(ctx context.Context, opts *CtrSpecGenOptions) (*specgen.SpecGenerator, error) {
@@ -220,7 +221,7 @@ func ToSpecGen(ctx context.Context, opts *CtrSpecGenOptions) (*specgen.SpecGener
 	}
 
 	if opts.NoPodPrefix {
-		s.Name = opts.Container.Name
+		s.Name = opts.NamePrefix + opts.Container.Name
 	} else {
 		s.Name = fmt.Sprintf("%s-%s", opts.PodName, opts.Container.Name)
 	}
@@ -3,10 +3,15 @@ package specgen
 import (
 	"errors"
 	"fmt"
+	pathpkg "path"
 	"strings"
 	"unicode"
 )
 
+// ErrUntranslatableWinPath is returned by ConvertWinMountPath for Windows
+// paths that cannot be reached from the machine.
+var ErrUntranslatableWinPath = errors.New("windows path cannot be used in the machine")
+
 func IsHostWinPath(path string) bool {
 	return shouldResolveWinPaths() && strings.HasPrefix(path, `\\`) || hasWinDriveScheme(path, 0) || winPathExists(path)
 }
@@ -44,16 +49,52 @@ func ConvertWinMountPath(path string) (string, error) {
 
 	// Strip extended marker prefix if present
 	path = strings.TrimPrefix(path, `\\?\`)
+	// \\?\UNC\server\share is the extended form of \\server\share
+	if rest, ok := strings.CutPrefix(path, `UNC\`); ok {
+		path = `\\` + rest
+	}
 
 	// Drive installed via wsl --mount
 	switch {
 	case strings.HasPrefix(path, `\\.\`):
 		path = "/mnt/wsl/" + path[4:]
+	case hasWinDriveScheme(path, 0) && (len(path) == 2 || (path[2] != '\\' && path[2] != '/')):
+		// C:test is relative to the current directory of the drive, only
+		// known to Windows clients which already made it absolute.
+		return path, fmt.Errorf("%w: drive-relative path %s, use an absolute path", ErrUntranslatableWinPath, path)
 	case len(path) > 1 && path[1] == ':':
 		path = "/mnt/" + strings.ToLower(path[0:1]) + path[2:]
+	case strings.HasPrefix(path, `\\`):
+		guestPath, err := wslShareGuestPath(path)
+		if err != nil {
+			return path, err
+		}
+		path = guestPath
 	default:
-		return path, errors.New("unsupported UNC path")
+		return path, fmt.Errorf("%w: relative path %s, use an absolute path", ErrUntranslatableWinPath, path)
 	}
 
-	return strings.ReplaceAll(path, `\`, "/"), nil
+	// Accept mixed separators and drop redundant or trailing ones
+	return pathpkg.Clean(strings.ReplaceAll(path, `\`, "/")), nil
+}
+
+// wslSharePrefixes are the UNC hosts under which Windows exposes the files of
+// the WSL distributions.
+var wslSharePrefixes = []string{`\\wsl$\`, `\\wsl.localhost\`}
+
+// wslShareGuestPath converts a path to the files of the WSL distribution of
+// the active machine (e.g. \\wsl$\podman-machine-default\home\user) into the
+// path inside that distribution. Network shares and the files of other
+// distributions cannot be reached from the machine.
+func wslShareGuestPath(path string) (string, error) {
+	for _, prefix := range wslSharePrefixes {
+		if len(path) > len(prefix) && strings.EqualFold(path[:len(prefix)], prefix) {
+			distro, rest, _ := strings.Cut(path[len(prefix):], `\`)
+			if active := activeWSLDistro(); active == "" || !strings.EqualFold(distro, active) {
+				return "", fmt.Errorf("%w: UNC path %s, %s is not the WSL distribution of the active machine", ErrUntranslatableWinPath, path, distro)
+			}
+			return `\` + rest, nil
+		}
+	}
+	return "", fmt.Errorf("%w: UNC path %s, network shares cannot be mounted in the machine", ErrUntranslatableWinPath, path)
 }
@@ -1,6 +1,8 @@
 package specgen
 
 import (
+	"os"
+
 	"go.podman.io/common/pkg/machine"
 	"go.podman.io/storage/pkg/fileutils"
 )
@@ -20,3 +22,9 @@ func resolveRelativeOnWindows(path string) string {
 func winPathExists(_ string) bool {
 	return false
 }
+
+// activeWSLDistro returns the WSL distribution podman runs in, which is the
+// machine when paths are resolved.
+func activeWSLDistro() string {
+	return os.Getenv("WSL_DISTRO_NAME")
+}
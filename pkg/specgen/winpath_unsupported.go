@@ -17,3 +17,7 @@ func resolveRelativeOnWindows(path string) string {
 func winPathExists(_ string) bool {
 	return false
 }
+
+func activeWSLDistro() string {
+	return ""
+}
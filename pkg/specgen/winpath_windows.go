@@ -1,9 +1,13 @@
 package specgen
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/containers/podman/v5/pkg/machine/env"
 	"github.com/sirupsen/logrus"
+	"go.podman.io/common/pkg/config"
 	"go.podman.io/storage/pkg/fileutils"
 )
 
@@ -28,3 +32,20 @@ func resolveRelativeOnWindows(path string) string {
 func winPathExists(path string) bool {
 	return fileutils.Exists(path) == nil
 }
+
+// activeWSLDistro returns the WSL distribution of the machine of the active
+// connection, or an empty string when it is not a machine connection.
+func activeWSLDistro() string {
+	cfg, err := config.Default()
+	if err != nil {
+		logrus.Debugf("problem reading the active connection: %s", err.Error())
+		return ""
+	}
+	name := os.Getenv("CONTAINER_CONNECTION")
+	con, err := cfg.GetConnection(name, name == "")
+	if err != nil || !con.IsMachine {
+		return ""
+	}
+	// The rootful connection of a machine is suffixed with -root
+	return env.WithPodmanPrefix(strings.TrimSuffix(con.Name, "-root"))
+}
@@ -0,0 +1,34 @@
+package specgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertWinMountPathWSLShare(t *testing.T) {
+	distro := activeWSLDistro()
+	if distro == "" {
+		t.Skip("the active connection is not a machine")
+	}
+
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{`\\wsl$\` + distro + `\home\user\test`, "/home/user/test"},
+		{`\\wsl.localhost\` + distro + `\test`, "/test"},
+		{`\\?\UNC\wsl.localhost\` + distro + `\test\`, "/test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ConvertWinMountPath(tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, path)
+		})
+	}
+
+	_, err := ConvertWinMountPath(`\\wsl$\` + distro + `-other\test`)
+	assert.ErrorIs(t, err, ErrUntranslatableWinPath)
+	assert.ErrorContains(t, err, "not the WSL distribution of the active machine")
+}